@@ -3,11 +3,13 @@ package diskoi
 import (
 	"context"
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"github.com/bwmarrin/discordgo"
 	"reflect"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 var (
@@ -15,15 +17,24 @@ var (
 	rTypeInteractCreate = reflect.TypeOf((*discordgo.InteractionCreate)(nil))
 	rTypeCommandOptions = reflect.TypeOf([]*discordgo.ApplicationCommandOptionChoice(nil))
 	rTypeMeta           = reflect.TypeOf((*MetaArgument)(nil))
+	rTypeUser           = reflect.TypeOf((*discordgo.User)(nil))
+	rTypeMember         = reflect.TypeOf((*discordgo.Member)(nil))
+	rTypeMessage        = reflect.TypeOf((*discordgo.Message)(nil))
+	rTypeComponentData  = reflect.TypeOf(discordgo.MessageComponentInteractionData{})
+	rTypeModalData      = reflect.TypeOf(discordgo.ModalSubmitInteractionData{})
 
-	rTypeIContext        = reflect.TypeOf((*context.Context)(nil)).Elem()
-	rTypeIUnmarshal      = reflect.TypeOf((*Unmarshal)(nil)).Elem()
-	rTypeIChannelType    = reflect.TypeOf((*ChannelType)(nil)).Elem()
-	rTypeICommandOptions = reflect.TypeOf((*CommandOptions)(nil)).Elem()
-	rTypeIError          = reflect.TypeOf((*error)(nil)).Elem()
+	rTypeIContext         = reflect.TypeOf((*context.Context)(nil)).Elem()
+	rTypeIUnmarshal       = reflect.TypeOf((*Unmarshal)(nil)).Elem()
+	rTypeIOptionUnmarshal = reflect.TypeOf((*OptionUnmarshal)(nil)).Elem()
+	rTypeIOptionType      = reflect.TypeOf((*OptionType)(nil)).Elem()
+	rTypeIValidator       = reflect.TypeOf((*Validator)(nil)).Elem()
+	rTypeIChannelType     = reflect.TypeOf((*ChannelType)(nil)).Elem()
+	rTypeICommandOptions  = reflect.TypeOf((*CommandOptions)(nil)).Elem()
+	rTypeIEnum            = reflect.TypeOf((*Enum)(nil)).Elem()
+	rTypeIError           = reflect.TypeOf((*error)(nil)).Elem()
 )
 
-const applicationCommandOptionDouble = 10 //type doubles fixme get constant from discord go
+const applicationCommandOptionDouble = discordgo.ApplicationCommandOptionNumber
 
 //analyzeCmdFn analyzes a given function, insure it matches expected function signatures for an execution function
 //and calls analyzeFunctionArgument to analyze the function arguments
@@ -34,14 +45,8 @@ func analyzeCmdFn(fn interface{}) ([]*fnArgument, reflect.Type, []*commandArgume
 		return nil, nil, nil, fmt.Errorf("given type %s(%s) is not type of func", typ.String(), typ.Kind().String())
 	}
 
-	if typ.NumOut() > 0 {
-		if typ.NumOut() > 1 {
-			return nil, nil, nil, fmt.Errorf("given function(%s) has %d outputs, expecting 0 or 1", signature(fn), typ.NumOut())
-		}
-		typOut := typ.Out(0)
-		if !typOut.Implements(rTypeIError) {
-			return nil, nil, nil, fmt.Errorf(`given function(%s) outputs "%s"(%s), expecting error`, signature(fn), typOut.String(), typOut.Kind().String())
-		}
+	if err := analyzeErrorOutput(fn); err != nil {
+		return nil, nil, nil, err
 	}
 
 	fnArgs, err := analyzeFunctionArgument(reflect.TypeOf(fn), nil)
@@ -62,6 +67,325 @@ func analyzeCmdFn(fn interface{}) ([]*fnArgument, reflect.Type, []*commandArgume
 	return fnArgs, cmdStruct, cmdArg, nil
 }
 
+//analyzeContextMenuFn analyzes a given function, insure it matches expected function signatures for a context menu function
+//the cTyp decides which target arguments are accepted, *discordgo.User and *discordgo.Member for user commands
+//and *discordgo.Message for message commands
+func analyzeContextMenuFn(fn interface{}, cTyp discordgo.ApplicationCommandType) ([]*fnArgument, error) {
+	typ := reflect.TypeOf(fn)
+	if typ.Kind() != reflect.Func {
+		return nil, fmt.Errorf("given type %s(%s) is not type of func", typ.String(), typ.Kind().String())
+	}
+	if err := analyzeErrorOutput(fn); err != nil {
+		return nil, err
+	}
+
+	fnArgs := make([]*fnArgument, 0, typ.NumIn())
+	for i := 0; i < typ.NumIn(); i++ {
+		fna := &fnArgument{}
+		at := typ.In(i)
+		switch {
+		case at == rTypeSession:
+			fna.typ = fnArgumentTypeSession
+		case at == rTypeInteractCreate:
+			fna.typ = fnArgumentTypeInteraction
+		case at == rTypeMeta:
+			fna.typ = fnArgumentTypeMeta
+		case at.Implements(rTypeIContext):
+			fna.typ = fnArgumentTypeContext
+		case at == rTypeUser && cTyp == discordgo.UserApplicationCommand:
+			fna.typ = fnArgumentTypeTargetUser
+		case at == rTypeMember && cTyp == discordgo.UserApplicationCommand:
+			fna.typ = fnArgumentTypeTargetMember
+		case at == rTypeMessage && cTyp == discordgo.MessageApplicationCommand:
+			fna.typ = fnArgumentTypeTargetMessage
+		default:
+			return nil, fmt.Errorf("unrecognized argument %s(#%d) on function, "+
+				"should be *discordgo.Session, *discordgo.InteractionCreate, *MetaArgument, context.Context or the command target", at.String(), i)
+		}
+		fnArgs = append(fnArgs, fna)
+	}
+	return fnArgs, nil
+}
+
+//analyzeComponentFn analyzes a given function, insure it matches expected function signatures for a component function
+//the data struct, if exist, must be the last argument and every field must map to a parameter in params
+//it returns the function arguments, the data struct and its arguments
+func analyzeComponentFn(fn interface{}, params []string) ([]*fnArgument, reflect.Type, []*commandArgument, error) {
+	typ := reflect.TypeOf(fn)
+	if typ.Kind() != reflect.Func {
+		return nil, nil, nil, fmt.Errorf("given type %s(%s) is not type of func", typ.String(), typ.Kind().String())
+	}
+	if err := analyzeErrorOutput(fn); err != nil {
+		return nil, nil, nil, err
+	}
+
+	var cmdStruct reflect.Type
+	var cmdArg []*commandArgument
+	fnArgs := make([]*fnArgument, 0, typ.NumIn())
+	for i := 0; i < typ.NumIn(); i++ {
+		fna := &fnArgument{}
+		at := typ.In(i)
+		original := at
+		switch {
+		case at == rTypeSession:
+			fna.typ = fnArgumentTypeSession
+		case at == rTypeInteractCreate:
+			fna.typ = fnArgumentTypeInteraction
+		case at == rTypeMeta:
+			fna.typ = fnArgumentTypeMeta
+		case at == rTypeComponentData:
+			fna.typ = fnArgumentTypeComponentData
+		case at.Implements(rTypeIContext):
+			fna.typ = fnArgumentTypeContext
+		default:
+			if i < typ.NumIn()-1 {
+				return nil, nil, nil, fmt.Errorf("unrecognized argument %s(#%d) on function, "+
+					"should be *discordgo.Session, *discordgo.InteractionCreate, *MetaArgument, context.Context "+
+					"or discordgo.MessageComponentInteractionData", original.String(), i)
+			}
+			if at.Kind() == reflect.Ptr {
+				fna.ptr = true
+				at = at.Elem()
+			}
+			if at.Kind() != reflect.Struct {
+				return nil, nil, nil, fmt.Errorf("unrecognized data struct argument %s(#%d) on function,"+
+					" should be type of struct for the last argument", original.String(), i)
+			}
+			args, err := analyzeCommandStruct(at, []int{})
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf(`analyzing component data(%s): %w`, at.String(), err)
+			}
+			for _, arg := range args {
+				//a parameter holds a single value, so it cant fill the numbered options of a slice field
+				if arg.count > 0 {
+					return nil, nil, nil, fmt.Errorf(`analyzing component data(%s): slice field "%s" is unsupported in component data`,
+						at.String(), arg.fieldName)
+				}
+				if !containsString(params, arg.Name) {
+					return nil, nil, nil, fmt.Errorf(`analyzing component data(%s): field "%s" named "%s" has no matching parameter`,
+						at.String(), arg.fieldName, arg.Name)
+				}
+			}
+			fna.typ = fnArgumentTypeData
+			fna.reflectTyp = at
+			cmdStruct, cmdArg = at, args
+		}
+		fnArgs = append(fnArgs, fna)
+	}
+	return fnArgs, cmdStruct, cmdArg, nil
+}
+
+//analyzeModalFn analyzes a given function, insure it matches expected function signatures for a modal submit function
+//the last argument must be the modal struct, which is analyzed by analyzeModalStruct to get the text inputs
+func analyzeModalFn(fn interface{}) ([]*fnArgument, reflect.Type, []*modalArgument, error) {
+	typ := reflect.TypeOf(fn)
+	if typ.Kind() != reflect.Func {
+		return nil, nil, nil, fmt.Errorf("given type %s(%s) is not type of func", typ.String(), typ.Kind().String())
+	}
+	if err := analyzeErrorOutput(fn); err != nil {
+		return nil, nil, nil, err
+	}
+
+	var modalStruct reflect.Type
+	var modalArgs []*modalArgument
+	fnArgs := make([]*fnArgument, 0, typ.NumIn())
+	for i := 0; i < typ.NumIn(); i++ {
+		fna := &fnArgument{}
+		at := typ.In(i)
+		original := at
+		switch {
+		case at == rTypeSession:
+			fna.typ = fnArgumentTypeSession
+		case at == rTypeInteractCreate:
+			fna.typ = fnArgumentTypeInteraction
+		case at == rTypeMeta:
+			fna.typ = fnArgumentTypeMeta
+		case at == rTypeModalData:
+			fna.typ = fnArgumentTypeModalData
+		case at.Implements(rTypeIContext):
+			fna.typ = fnArgumentTypeContext
+		default:
+			if i < typ.NumIn()-1 {
+				return nil, nil, nil, fmt.Errorf("unrecognized argument %s(#%d) on function, "+
+					"should be *discordgo.Session, *discordgo.InteractionCreate, *MetaArgument, context.Context "+
+					"or discordgo.ModalSubmitInteractionData", original.String(), i)
+			}
+			if at.Kind() == reflect.Ptr {
+				fna.ptr = true
+				at = at.Elem()
+			}
+			if at.Kind() != reflect.Struct {
+				return nil, nil, nil, fmt.Errorf("unrecognized modal struct argument %s(#%d) on function,"+
+					" should be type of struct for the last argument", original.String(), i)
+			}
+			args, err := analyzeModalStruct(at, []int{})
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf(`analyzing modal(%s): %w`, at.String(), err)
+			}
+			fna.typ = fnArgumentTypeData
+			fna.reflectTyp = at
+			modalStruct, modalArgs = at, args
+		}
+		fnArgs = append(fnArgs, fna)
+	}
+	if modalStruct == nil {
+		return nil, nil, nil, fmt.Errorf("given function(%s) is missing the modal struct as the last argument", signature(fn))
+	}
+	if len(modalArgs) == 0 || len(modalArgs) > maxModalInputs {
+		return nil, nil, nil, fmt.Errorf(`modal(%s) has %d text inputs, expecting 1 to %d`,
+			modalStruct.String(), len(modalArgs), maxModalInputs)
+	}
+	seen := make(map[string]string, len(modalArgs))
+	for _, arg := range modalArgs {
+		if other, ok := seen[arg.Name]; ok {
+			return nil, nil, nil, fmt.Errorf(`modal(%s) custom id "%s" of field "%s" is already used by field "%s"`,
+				modalStruct.String(), arg.Name, arg.fieldName, other)
+		}
+		seen[arg.Name] = arg.fieldName
+	}
+	return fnArgs, modalStruct, modalArgs, nil
+}
+
+//analyzeModalStruct analyzes the fields of given modal struct and recursively path into embedded structs
+//it works the same way as analyzeCommandStruct, but calls analyzeModalField for each field
+func analyzeModalStruct(typ reflect.Type, pre []int) ([]*modalArgument, error) {
+	args := make([]*modalArgument, 0, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		pos := append(append(make([]int, 0, len(pre)+1), pre...), i)
+		if !f.IsExported() {
+			return nil, fmt.Errorf(`unsupported unexported field in "%s.%s"`, typ.String(), f.Name)
+		}
+		if f.Anonymous {
+			if f.Type.Kind() == reflect.Ptr {
+				return nil, fmt.Errorf(`unsupported anonymous field with pointer in "%s.%s"`, typ.String(), f.Name)
+			}
+			a, err := analyzeModalStruct(f.Type, pos)
+			if err != nil {
+				return nil, fmt.Errorf(`in "%s": %w`, typ.String(), err)
+			}
+			args = append(args, a...)
+			continue
+		}
+
+		arg, err := analyzeModalField(f)
+		if err != nil {
+			return nil, fmt.Errorf(`analyzing field "%s.%s": %w`, typ.String(), f.Name, err)
+		}
+		arg.fieldIndex = pos
+		args = append(args, arg)
+	}
+	return args, nil
+}
+
+const (
+	maxModalInputs      = 5
+	maxModalLabelLength = 45
+	maxModalInputLength = 4000
+)
+
+//analyzeModalField analyze a "reflect.StructField" of a modal struct and returns the text input for said field
+//text inputs are identified by their name, which defaults to the lowercase field name, and the label defaults to the field name
+func analyzeModalField(f reflect.StructField) (*modalArgument, error) {
+	tag, ok := f.Tag.Lookup(magicTag)
+
+	arg := &modalArgument{
+		commandArgument: &commandArgument{
+			fieldName: f.Name,
+			Name:      strings.ToLower(f.Name),
+		},
+		Label: f.Name,
+		Style: discordgo.TextInputShort,
+	}
+
+	if ok {
+		entries, err := readTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		for _, ent := range entries {
+			key, value := ent[0], ent[1]
+			switch key {
+			case "name":
+				arg.Name = value
+			case "label":
+				arg.Label = value
+			case "placeholder":
+				arg.Placeholder = value
+			case "value":
+				arg.Value = value
+			case "style":
+				switch value {
+				case "short":
+					arg.Style = discordgo.TextInputShort
+				case "paragraph":
+					arg.Style = discordgo.TextInputParagraph
+				default:
+					return nil, fmt.Errorf(`unrecognized style "%s", should be "short" or "paragraph"`, value)
+				}
+			case "required":
+				arg.Required, err = parseTagBool(value)
+				if err != nil {
+					return nil, err
+				}
+			case "min_length", "max_length":
+				l, err := strconv.Atoi(value)
+				if err != nil {
+					return nil, fmt.Errorf(`converting "%s" into int: %w`, value, err)
+				}
+				if l < 0 || l > maxModalInputLength {
+					return nil, fmt.Errorf(`%s "%d" out of range, expecting 0 to %d`, key, l, maxModalInputLength)
+				}
+				if key == "min_length" {
+					arg.MinLength = l
+				} else {
+					arg.MaxLength = l
+				}
+			default:
+				return nil, fmt.Errorf("unrecognized tag \"%s\" with value \"%s\"", key, value)
+			}
+		}
+	}
+	if l := utf8.RuneCountInString(arg.Label); l == 0 || l > maxModalLabelLength {
+		return nil, fmt.Errorf(`label "%s" should be 1 to %d characters long`, arg.Label, maxModalLabelLength)
+	}
+	if arg.MaxLength != 0 && arg.MinLength > arg.MaxLength {
+		return nil, fmt.Errorf(`min_length "%d" is larger than max_length "%d"`, arg.MinLength, arg.MaxLength)
+	}
+
+	elmT := f.Type
+	if elmT.Kind() == reflect.Ptr {
+		elmT = f.Type.Elem()
+	}
+	switch elmT.Kind() {
+	case reflect.String:
+		arg.cType = discordgo.ApplicationCommandOptionString
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		arg.cType = discordgo.ApplicationCommandOptionInteger
+	case reflect.Float32, reflect.Float64:
+		arg.cType = applicationCommandOptionDouble
+	default:
+		return nil, fmt.Errorf(`unsupported kind "%s", modal fields should be string or number`, f.Type.String())
+	}
+	return arg, nil
+}
+
+//analyzeErrorOutput insure the given function outputs nothing or only an error
+func analyzeErrorOutput(fn interface{}) error {
+	typ := reflect.TypeOf(fn)
+	if typ.NumOut() > 0 {
+		if typ.NumOut() > 1 {
+			return fmt.Errorf("given function(%s) has %d outputs, expecting 0 or 1", signature(fn), typ.NumOut())
+		}
+		typOut := typ.Out(0)
+		if !typOut.Implements(rTypeIError) {
+			return fmt.Errorf(`given function(%s) outputs "%s"(%s), expecting error`, signature(fn), typOut.String(), typOut.Kind().String())
+		}
+	}
+	return nil
+}
+
 //analyzeAutocompleteFunction analyzes a given function, insure it matches expected function signatures for an autocomplete function
 //it also takes in an expected data type of the main executor
 //it returns analyzeFunctionArgument which returns a list of function arguments
@@ -121,6 +445,7 @@ func analyzeFunctionArgument(typ reflect.Type, expected reflect.Type) ([]*fnArgu
 					"should be *discordgo.Session, *discordgo.InteractionCreate, *MetaArgument or something that implement diskoi.Unmarshal", original.String(), i)
 			}
 			if at.Kind() == reflect.Ptr {
+				fna.ptr = true
 				at = at.Elem()
 			}
 			if at.Kind() != reflect.Struct {
@@ -163,6 +488,14 @@ func analyzeCommandStruct(typ reflect.Type, pre []int) ([]*commandArgument, erro
 			cmdArgs = append(cmdArgs, a...)
 			continue
 		}
+		if isOptionGroup(f.Type) {
+			a, err := analyzeOptionGroup(f, pos)
+			if err != nil {
+				return nil, fmt.Errorf(`analyzing field "%s.%s": %w`, typ.String(), f.Name, err)
+			}
+			cmdArgs = append(cmdArgs, a...)
+			continue
+		}
 
 		py, err := analyzeCommandArgumentField(f)
 		if err != nil {
@@ -170,6 +503,12 @@ func analyzeCommandStruct(typ reflect.Type, pre []int) ([]*commandArgument, erro
 		}
 		if py != nil {
 			py.fieldIndex = pos
+			if !tagHasKey(f.Tag, "name") {
+				fieldName, slice := f.Name, py.count > 0
+				py.naming = func(s NamingStrategy) string {
+					return optionName(s, fieldName, slice)
+				}
+			}
 			cmdArgs = append(cmdArgs, py)
 		}
 	}
@@ -178,6 +517,112 @@ func analyzeCommandStruct(typ reflect.Type, pre []int) ([]*commandArgument, erro
 
 const magicTag = "diskoi"
 
+//optionName names the option of a field with the strategy
+//numbered options of slices are named after a single element, e.g. "Users" into "user1", "user2"...
+func optionName(s NamingStrategy, fieldName string, slice bool) string {
+	name := s(fieldName)
+	if slice {
+		name = singularName(name)
+	}
+	return name
+}
+
+//singularName strips the plural "s" of a name when that's unambiguous, "users" into "user"
+//names like "status", "address", "analysis", "boxes" or "categories" are kept as they are
+func singularName(name string) string {
+	if len(name) < 2 || !strings.HasSuffix(name, "s") {
+		return name
+	}
+	for _, suffix := range []string{"ss", "us", "is", "ies", "ses", "xes", "zes", "ches", "shes"} {
+		if strings.HasSuffix(name, suffix) {
+			return name
+		}
+	}
+	return strings.TrimSuffix(name, "s")
+}
+
+//tagHasKey checks if the key is in the diskoi tag, tags that cant be read have no keys
+func tagHasKey(tag reflect.StructTag, key string) bool {
+	t, ok := tag.Lookup(magicTag)
+	if !ok {
+		return false
+	}
+	entries, err := readTag(t)
+	if err != nil {
+		return false
+	}
+	for _, ent := range entries {
+		if ent[0] == key {
+			return true
+		}
+	}
+	return false
+}
+
+//groupPrefixSeparator joins the prefix of an option group to the names of its options
+const groupPrefixSeparator = "_"
+
+//isOptionGroup checks if the type is a struct of options, rather than a struct an option is reconstructed into
+func isOptionGroup(typ reflect.Type) bool {
+	if typ.Kind() != reflect.Struct {
+		return false
+	}
+	for _, t := range optionTypes {
+		if typ == t {
+			return false
+		}
+	}
+	if _, c := findConverter(typ); c != nil {
+		return false
+	}
+	ptr := reflect.PtrTo(typ)
+	return typ != reflect.TypeOf(Attachment{}) && typ != rTypeMember.Elem() && !ptr.Implements(rTypeIOptionUnmarshal) &&
+		!ptr.Implements(rTypeIChannelType) && !ptr.Implements(rTypeICommandOptions)
+}
+
+//analyzeOptionGroup flattens the options of a named struct field, the options are prefixed by the field name
+//e.g. "Range struct{Start, End int}" into "range_start" and "range_end", or by the prefix tag, where "prefix:" removes it
+func analyzeOptionGroup(f reflect.StructField, pos []int) ([]*commandArgument, error) {
+	groupName := f.Name
+	prefixOf := func(s NamingStrategy) string {
+		return s(groupName) + groupPrefixSeparator
+	}
+	if tag, ok := f.Tag.Lookup(magicTag); ok {
+		entries, err := readTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		for _, ent := range entries {
+			key, value := ent[0], ent[1]
+			switch key {
+			case "prefix":
+				prefix := value
+				prefixOf = func(NamingStrategy) string {
+					return prefix
+				}
+			default:
+				return nil, fmt.Errorf("unrecognized tag \"%s\" with value \"%s\" on option group", key, value)
+			}
+		}
+	}
+	args, err := analyzeCommandStruct(f.Type, pos)
+	if err != nil {
+		return nil, err
+	}
+	for _, arg := range args {
+		name, naming := arg.Name, arg.naming
+		arg.naming = func(s NamingStrategy) string {
+			if naming != nil {
+				return prefixOf(s) + naming(s)
+			}
+			return prefixOf(s) + name
+		}
+		arg.Name = arg.naming(NamingLower)
+		arg.fieldName = f.Name + "." + arg.fieldName
+	}
+	return args, nil
+}
+
 //analyzeCommandArgumentField analyze a "reflect.StructField"
 //and returns either commandArgument or specialArgument for said field
 //this is iteratively called by analyzeCommandStruct for each field discovered inside the command struct
@@ -188,42 +633,66 @@ func analyzeCommandArgumentField(f reflect.StructField) (*commandArgument, error
 		fieldName: f.Name,
 		Name:      strings.ToLower(f.Name),
 	}
+	//constraints and the default are stored by tag name, and parsed once the option type is known
+	constraints := map[string]string{}
+	var defaultTag, choicesTag *string
 
 	if ok {
-		r := csv.NewReader(strings.NewReader(tag))
-		r.Comment = 0
-		r.FieldsPerRecord = -1
-		r.TrimLeadingSpace = true
-
-		allEntries, err := r.ReadAll()
+		entries, err := readTag(tag)
 		if err != nil {
-			return nil, fmt.Errorf(`parsing tag: %s`, err.Error())
-		}
-		for _, subEntry := range allEntries {
-			for _, ent := range subEntry {
-				key, value := splitTxt(ent)
-				switch key {
-				case "name":
-					arg.Name = value
-				case "description":
-					arg.Description = value
-				case "required":
-					if len(value) == 0 {
-						arg.Required = true
-					} else {
-						b, err := strconv.ParseBool(value)
-						if err != nil {
-							return nil, fmt.Errorf(`converting "%s" into bool: %w`, value, err)
-						}
-						arg.Required = b
-					}
-				default:
-					return nil, fmt.Errorf("unrecognized tag \"%s\" with value \"%s\"", key, value)
+			return nil, err
+		}
+		for _, ent := range entries {
+			key, value := ent[0], ent[1]
+			switch key {
+			case "name":
+				arg.Name = value
+			case "description":
+				arg.Description = value
+			case "name_key":
+				arg.nameKey = value
+			case "description_key":
+				arg.descriptionKey = value
+			case "min", "max", "min_length", "max_length":
+				constraints[key] = value
+			case "default":
+				v := value
+				defaultTag = &v
+			case "choices":
+				v := value
+				choicesTag = &v
+			case "required":
+				arg.Required, err = parseTagBool(value)
+				if err != nil {
+					return nil, err
 				}
+			case "order":
+				arg.order, err = strconv.Atoi(value)
+				if err != nil {
+					return nil, fmt.Errorf(`tag "order": converting "%s" into int: %w`, value, err)
+				}
+			default:
+				return nil, fmt.Errorf("unrecognized tag \"%s\" with value \"%s\"", key, value)
 			}
 		}
 	}
 
+	//slices are expanded into numbered options, unless the slice type itself is parsed from a single option
+	if _, c := findConverter(f.Type); f.Type.Kind() == reflect.Slice && c == nil &&
+		!reflect.PtrTo(f.Type).Implements(rTypeIOptionUnmarshal) {
+		if err := analyzeSliceCount(arg, constraints); err != nil {
+			return nil, err
+		}
+		if defaultTag != nil {
+			return nil, fmt.Errorf(`default cant be set on slice field "%s"`, f.Name)
+		}
+		//the default name is singular, as the options are numbered
+		if arg.Name == strings.ToLower(f.Name) {
+			arg.Name = optionName(NamingLower, f.Name, true)
+		}
+		f.Type = f.Type.Elem()
+	}
+
 	if f.Type.Implements(rTypeIChannelType) {
 		v := reflect.New(f.Type.Elem()).Elem()
 		ch := v.Interface().(ChannelType)
@@ -239,6 +708,17 @@ func analyzeCommandArgumentField(f reflect.StructField) (*commandArgument, error
 	if elmT.Kind() == reflect.Ptr {
 		elmT = f.Type.Elem()
 	}
+	//types with a converter are string options, so the constraints, choices and default apply to the raw string
+	if arg.convertTyp, arg.converter = findConverter(f.Type); arg.converter != nil {
+		elmT = reflect.TypeOf("")
+	} else if reflect.PtrTo(elmT).Implements(rTypeIOptionUnmarshal) {
+		//the field is analyzed as the type the option would be reconstructed into
+		typ, err := unmarshalOptionType(elmT)
+		if err != nil {
+			return nil, err
+		}
+		arg.unmarshal, elmT = true, typ
+	}
 	switch elmT.Kind() {
 	case reflect.String:
 		arg.cType = discordgo.ApplicationCommandOptionString
@@ -253,12 +733,14 @@ func analyzeCommandArgumentField(f reflect.StructField) (*commandArgument, error
 		switch {
 		case elmT == reflect.TypeOf(discordgo.Channel{}):
 			arg.cType = discordgo.ApplicationCommandOptionChannel
-		case elmT == reflect.TypeOf(discordgo.User{}):
+		case elmT == reflect.TypeOf(discordgo.User{}), elmT == reflect.TypeOf(discordgo.Member{}):
 			arg.cType = discordgo.ApplicationCommandOptionUser
 		case elmT == reflect.TypeOf(discordgo.Role{}):
 			arg.cType = discordgo.ApplicationCommandOptionRole
 		case elmT == reflect.TypeOf(Mentionable{}):
 			arg.cType = discordgo.ApplicationCommandOptionMentionable
+		case elmT == reflect.TypeOf(discordgo.MessageAttachment{}), elmT == reflect.TypeOf(Attachment{}):
+			arg.cType = discordgo.ApplicationCommandOptionAttachment
 		default:
 			if len(arg.ChannelTypes) == 0 || len(arg.Choices) == 0 {
 				return nil, fmt.Errorf(`unrecognized struct "%s"`, f.Type.String())
@@ -267,9 +749,390 @@ func analyzeCommandArgumentField(f reflect.StructField) (*commandArgument, error
 	default:
 		return nil, fmt.Errorf(`unsupported kind "%s"`, f.Type.String())
 	}
+	if err := analyzeArgumentConstraints(arg, elmT, constraints); err != nil {
+		return nil, err
+	}
+	if err := analyzeArgumentChoices(arg, elmT, choicesTag); err != nil {
+		return nil, err
+	}
+	if defaultTag != nil && arg.converter != nil {
+		if err := setConvertedDefault(arg, *defaultTag); err != nil {
+			return nil, err
+		}
+	} else if defaultTag != nil {
+		v, err := parseTagValue(elmT, *defaultTag)
+		if err != nil {
+			return nil, fmt.Errorf(`tag "default": %w`, err)
+		}
+		if err := setArgumentDefault(arg, v); err != nil {
+			return nil, err
+		}
+	}
 	return arg, nil
 }
 
+//maxOptions is the maximum number of options a command can have
+const maxOptions = 25
+
+//analyzeSliceCount takes the max and min tags of a slice field as the number of options and required options
+//so they no longer apply to the elements
+func analyzeSliceCount(arg *commandArgument, constraints map[string]string) error {
+	value, ok := constraints["max"]
+	if !ok {
+		return errors.New(`slice fields require the tag "max" for the number of options`)
+	}
+	count, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf(`tag "max": converting "%s" into int: %w`, value, err)
+	}
+	if count < 1 || count > maxOptions {
+		return fmt.Errorf(`tag "max": number of options should be 1 to %d, not %d`, maxOptions, count)
+	}
+	arg.count = count
+	delete(constraints, "max")
+	if value, ok = constraints["min"]; ok {
+		arg.minCount, err = strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf(`tag "min": converting "%s" into int: %w`, value, err)
+		}
+		if arg.minCount < 0 || arg.minCount > count {
+			return fmt.Errorf(`tag "min": number of required options should be 0 to %d, not %d`, count, arg.minCount)
+		}
+		delete(constraints, "min")
+	}
+	return nil
+}
+
+//optionTypes are the types options are reconstructed into, by the type of the option
+var optionTypes = map[discordgo.ApplicationCommandOptionType]reflect.Type{
+	discordgo.ApplicationCommandOptionString:      reflect.TypeOf(""),
+	discordgo.ApplicationCommandOptionInteger:     reflect.TypeOf(int64(0)),
+	discordgo.ApplicationCommandOptionBoolean:     reflect.TypeOf(false),
+	discordgo.ApplicationCommandOptionUser:        rTypeUser.Elem(),
+	discordgo.ApplicationCommandOptionChannel:     reflect.TypeOf(discordgo.Channel{}),
+	discordgo.ApplicationCommandOptionRole:        reflect.TypeOf(discordgo.Role{}),
+	discordgo.ApplicationCommandOptionMentionable: reflect.TypeOf(Mentionable{}),
+	applicationCommandOptionDouble:                reflect.TypeOf(float64(0)),
+	discordgo.ApplicationCommandOptionAttachment:  reflect.TypeOf(discordgo.MessageAttachment{}),
+}
+
+//unmarshalOptionType finds the type the option of a type implementing OptionUnmarshal is reconstructed into
+//the option type is read from the zero value, so OptionType shouldn't depend on the value
+func unmarshalOptionType(typ reflect.Type) (reflect.Type, error) {
+	var ot OptionType
+	switch {
+	case typ.Implements(rTypeIOptionType):
+		ot = reflect.Zero(typ).Interface().(OptionType)
+	case reflect.PtrTo(typ).Implements(rTypeIOptionType):
+		ot = reflect.New(typ).Interface().(OptionType)
+	default:
+		return optionTypes[discordgo.ApplicationCommandOptionString], nil
+	}
+	cType := ot.DiskoiOptionType()
+	t, ok := optionTypes[cType]
+	if !ok {
+		return nil, fmt.Errorf(`unsupported option type "%v" of "%s"`, cType, typ.String())
+	}
+	return t, nil
+}
+
+const (
+	maxOptionChoices         = 25
+	maxOptionChoiceLength    = 100
+	choiceSeparator          = "|"
+	choiceNameValueSeparator = "="
+)
+
+//analyzeArgumentChoices sets the choices from the choices tag, e.g. "choices:low=1|medium=2|high=3"
+//or from the values of the type if it implements Enum, the tag takes priority over the enum
+//a choice without a value in the tag, e.g. "choices:red|blue", uses the name as the value
+func analyzeArgumentChoices(arg *commandArgument, typ reflect.Type, tag *string) error {
+	var values []reflect.Value
+	var names []string
+	switch enum, isEnum := enumOf(typ); {
+	case tag != nil:
+		for _, c := range strings.Split(*tag, choiceSeparator) {
+			name, raw := c, c
+			if i := strings.Index(c, choiceNameValueSeparator); i >= 0 {
+				name, raw = c[:i], c[i+len(choiceNameValueSeparator):]
+			}
+			v, err := parseTagValue(typ, raw)
+			if err != nil {
+				return fmt.Errorf(`tag "choices": choice "%s": %w`, name, err)
+			}
+			names = append(names, name)
+			values = append(values, v)
+		}
+	case isEnum:
+		for _, s := range enum.DiskoiEnumValues() {
+			v := reflect.ValueOf(s)
+			if v.Kind() == reflect.Ptr && v.Type().Elem() == typ {
+				v = v.Elem()
+			}
+			if v.Type() != typ {
+				return fmt.Errorf(`enum value "%s" is type of %s, expecting %s`, s.String(), v.Type().String(), typ.String())
+			}
+			names = append(names, s.String())
+			values = append(values, v)
+		}
+	default:
+		return nil
+	}
+	if arg.cType != discordgo.ApplicationCommandOptionString && arg.cType != discordgo.ApplicationCommandOptionInteger &&
+		arg.cType != applicationCommandOptionDouble {
+		return fmt.Errorf(`choices are only supported on string, integer and number fields, not "%s"`, typ.String())
+	}
+	if len(values) > maxOptionChoices {
+		return fmt.Errorf(`%d choices given, expecting at most %d`, len(values), maxOptionChoices)
+	}
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, len(values))
+	for i, v := range values {
+		if len(names[i]) == 0 || utf8.RuneCountInString(names[i]) > maxOptionChoiceLength {
+			return fmt.Errorf(`choice name "%s" should be 1 to %d characters long`, names[i], maxOptionChoiceLength)
+		}
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: names[i], Value: choiceValue(v)})
+	}
+	arg.Choices = choices
+	return nil
+}
+
+//enumOf returns the type as an Enum, if either the type or its pointer implements it
+func enumOf(typ reflect.Type) (Enum, bool) {
+	switch {
+	case typ.Implements(rTypeIEnum):
+		return reflect.New(typ).Elem().Interface().(Enum), true
+	case reflect.PtrTo(typ).Implements(rTypeIEnum):
+		return reflect.New(typ).Interface().(Enum), true
+	default:
+		return nil, false
+	}
+}
+
+//choiceValue converts the value into the type discord expects for a choice
+func choiceValue(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	default:
+		return v.String()
+	}
+}
+
+//parseTagValue parses the value of a tag into a value of typ, only scalar kinds are supported
+func parseTagValue(typ reflect.Type, value string) (reflect.Value, error) {
+	v := reflect.New(typ).Elem()
+	switch typ.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf(`converting "%s" into bool: %w`, value, err)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, typ.Bits())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf(`converting "%s" into %s: %w`, value, typ.String(), err)
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, 10, typ.Bits())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf(`converting "%s" into %s: %w`, value, typ.String(), err)
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, typ.Bits())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf(`converting "%s" into %s: %w`, value, typ.String(), err)
+		}
+		v.SetFloat(f)
+	default:
+		return reflect.Value{}, fmt.Errorf(`unsupported on "%s"`, typ.String())
+	}
+	return v, nil
+}
+
+//setArgumentDefault sets the default of the argument, after checking it against the constraints of the argument
+//the invalid value removes the default
+func setArgumentDefault(arg *commandArgument, v reflect.Value) error {
+	if !v.IsValid() {
+		arg.defaultValue = v
+		return nil
+	}
+	if arg.Required {
+		return fmt.Errorf(`default cant be set on required field "%s"`, arg.fieldName)
+	}
+	if arg.unmarshal {
+		return fmt.Errorf(`default cant be set on field "%s" implementing OptionUnmarshal`, arg.fieldName)
+	}
+	var f float64
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		f = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		f = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		f = v.Float()
+	case reflect.String:
+		l := utf8.RuneCountInString(v.String())
+		if (arg.MinLength != nil && l < *arg.MinLength) || (arg.MaxLength != nil && l > *arg.MaxLength) {
+			return fmt.Errorf(`length of default "%v" is out of range of field "%s"`, v.Interface(), arg.fieldName)
+		}
+	}
+	if (arg.MinValue != nil && f < *arg.MinValue) || (arg.MaxValue != nil && f > *arg.MaxValue) {
+		return fmt.Errorf(`default "%v" is out of range of field "%s"`, v.Interface(), arg.fieldName)
+	}
+	if len(arg.Choices) > 0 && !choicesContain(arg.Choices, choiceValue(v)) {
+		return fmt.Errorf(`default "%v" is not one of the choices of field "%s"`, v.Interface(), arg.fieldName)
+	}
+	arg.defaultValue = v
+	return nil
+}
+
+//discord only accepts integers between -2^53 and 2^53
+const (
+	minOptionInteger = -1 << 53
+	maxOptionInteger = 1 << 53
+)
+
+//maxOptionLength is the maximum length of string options
+const maxOptionLength = 6000
+
+//analyzeArgumentConstraints parses the min, max, min_length and max_length tags into the argument
+//bounds are checked against the option type and the kind of the field, so they cant overflow the field
+func analyzeArgumentConstraints(arg *commandArgument, typ reflect.Type, constraints map[string]string) error {
+	for _, key := range []string{"min", "max", "min_length", "max_length"} {
+		value, ok := constraints[key]
+		if !ok {
+			continue
+		}
+		switch key {
+		case "min", "max":
+			if arg.cType != discordgo.ApplicationCommandOptionInteger && arg.cType != applicationCommandOptionDouble {
+				return fmt.Errorf(`tag "%s" is only supported on integer and number fields, not "%s"`, key, typ.String())
+			}
+			f, err := parseTagNumber(typ, value)
+			if err != nil {
+				return fmt.Errorf(`tag "%s": %w`, key, err)
+			}
+			//discordgo omits a max value of 0, so discord would never enforce it
+			if key == "max" && f == 0 {
+				return errors.New(`tag "max": 0 is not supported, discordgo omits a max value of 0`)
+			}
+			if key == "min" {
+				arg.MinValue = &f
+			} else {
+				arg.MaxValue = &f
+			}
+		case "min_length", "max_length":
+			if arg.cType != discordgo.ApplicationCommandOptionString {
+				return fmt.Errorf(`tag "%s" is only supported on string fields, not "%s"`, key, typ.String())
+			}
+			l, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf(`tag "%s": converting "%s" into int: %w`, key, value, err)
+			}
+			if l < 0 || l > maxOptionLength || (key == "max_length" && l == 0) {
+				return fmt.Errorf(`tag "%s": "%d" out of range, expecting 0 to %d`, key, l, maxOptionLength)
+			}
+			if key == "min_length" {
+				arg.MinLength = &l
+			} else {
+				arg.MaxLength = &l
+			}
+		}
+	}
+	if arg.MinValue != nil && arg.MaxValue != nil && *arg.MinValue > *arg.MaxValue {
+		return fmt.Errorf(`min "%v" is larger than max "%v"`, *arg.MinValue, *arg.MaxValue)
+	}
+	if arg.MinLength != nil && arg.MaxLength != nil && *arg.MinLength > *arg.MaxLength {
+		return fmt.Errorf(`min_length "%d" is larger than max_length "%d"`, *arg.MinLength, *arg.MaxLength)
+	}
+	return nil
+}
+
+//parseTagNumber parses the value of a numeric tag, the value must fit into the kind of typ
+func parseTagNumber(typ reflect.Type, value string) (float64, error) {
+	v := reflect.New(typ).Elem()
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf(`converting "%s" into int: %w`, value, err)
+		}
+		if v.OverflowInt(i) || i < minOptionInteger || i > maxOptionInteger {
+			return 0, fmt.Errorf(`"%s" overflows %s`, value, typ.String())
+		}
+		return float64(i), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf(`converting "%s" into uint: %w`, value, err)
+		}
+		if v.OverflowUint(u) || u > maxOptionInteger {
+			return 0, fmt.Errorf(`"%s" overflows %s`, value, typ.String())
+		}
+		return float64(u), nil
+	default:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return 0, fmt.Errorf(`converting "%s" into float: %w`, value, err)
+		}
+		if v.OverflowFloat(f) {
+			return 0, fmt.Errorf(`"%s" overflows %s`, value, typ.String())
+		}
+		return f, nil
+	}
+}
+
+//readTag parses the csv formatted tag, and returns every entry split into key and value
+func readTag(tag string) ([][2]string, error) {
+	r := csv.NewReader(strings.NewReader(tag))
+	r.Comment = 0
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	allEntries, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf(`parsing tag: %s`, err.Error())
+	}
+	var entries [][2]string
+	for _, subEntry := range allEntries {
+		for _, ent := range subEntry {
+			key, value := splitTxt(ent)
+			entries = append(entries, [2]string{key, value})
+		}
+	}
+	return entries, nil
+}
+
+//parseTagBool parses the value of a boolean tag, where an empty value means true
+func parseTagBool(value string) (bool, error) {
+	if len(value) == 0 {
+		return true, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf(`converting "%s" into bool: %w`, value, err)
+	}
+	return b, nil
+}
+
+func containsString(s []string, str string) bool {
+	for _, v := range s {
+		if v == str {
+			return true
+		}
+	}
+	return false
+}
+
 func splitTxt(str string) (string, string) {
 	split := strings.SplitN(str, ":", 2)
 	if len(split) >= 2 {
@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"reflect"
 	"regexp"
+	"strings"
 	"testing"
 )
 
@@ -232,6 +233,140 @@ func TestAnalyzeAutocompleteFunction(t *testing.T) {
 	}
 }
 
+func TestAnalyzeContextMenuFn(t *testing.T) {
+	cases := []struct {
+		name  string
+		fn    interface{}
+		cType discordgo.ApplicationCommandType
+
+		wantArg []fnArgument
+		wantErr *regexp.Regexp
+	}{
+		{
+			name: "user",
+			fn: func(s *discordgo.Session, i *discordgo.InteractionCreate, ctx context.Context, u *discordgo.User, m *discordgo.Member) error {
+				return nil
+			},
+			cType: discordgo.UserApplicationCommand,
+			wantArg: []fnArgument{{typ: fnArgumentTypeSession}, {typ: fnArgumentTypeInteraction}, {typ: fnArgumentTypeContext},
+				{typ: fnArgumentTypeTargetUser}, {typ: fnArgumentTypeTargetMember}},
+		}, {
+			name:    "message",
+			fn:      func(m *MetaArgument, msg *discordgo.Message) {},
+			cType:   discordgo.MessageApplicationCommand,
+			wantArg: []fnArgument{{typ: fnArgumentTypeMeta}, {typ: fnArgumentTypeTargetMessage}},
+		}, {
+			name:    "err non func",
+			fn:      "foo",
+			cType:   discordgo.UserApplicationCommand,
+			wantErr: regexp.MustCompile("^given type .*?\\) is not type of func"),
+		}, {
+			name:    "err unexpected output type",
+			fn:      func() string { return "" },
+			cType:   discordgo.UserApplicationCommand,
+			wantErr: regexp.MustCompile("^given function.*?\\) outputs \".*?\".*?\\), expecting error"),
+		}, {
+			name:    "err wrong target",
+			fn:      func(msg *discordgo.Message) {},
+			cType:   discordgo.UserApplicationCommand,
+			wantErr: regexp.MustCompile(`^unrecognized argument \*discordgo\.Message\(#0\) on function`),
+		}, {
+			name:    "err data struct",
+			fn:      func(e Embeddable1) {},
+			cType:   discordgo.MessageApplicationCommand,
+			wantErr: regexp.MustCompile(`^unrecognized argument diskoi\.Embeddable1\(#0\) on function`),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+			args, err := analyzeContextMenuFn(tc.fn, tc.cType)
+			if tc.wantErr != nil {
+				r.Regexp(tc.wantErr, err)
+			} else {
+				r.Nil(err)
+			}
+			if len(tc.wantArg) != 0 {
+				r.Equal(len(tc.wantArg), len(args), "results and want argument length should be equal")
+				for i, arg := range tc.wantArg {
+					r.Equal(arg.typ, args[i].typ, fmt.Sprintf("expected fnArgumentType to be the same on: #%d", i))
+				}
+			} else {
+				r.Empty(args)
+			}
+		})
+	}
+}
+
+func TestAnalyzeComponentFn(t *testing.T) {
+	type componentData struct {
+		PollID int `diskoi:"name:pollID"`
+		Choice string
+	}
+	cases := []struct {
+		name   string
+		fn     interface{}
+		params []string
+
+		wantArg  []fnArgument
+		wantType reflect.Type
+		wantErr  *regexp.Regexp
+	}{
+		{
+			name: "simple",
+			fn: func(s *discordgo.Session, cd discordgo.MessageComponentInteractionData, d *componentData) error {
+				return nil
+			},
+			params: []string{"pollID", "choice"},
+			wantArg: []fnArgument{{typ: fnArgumentTypeSession}, {typ: fnArgumentTypeComponentData},
+				{typ: fnArgumentTypeData, reflectTyp: reflect.TypeOf(componentData{})}},
+			wantType: reflect.TypeOf(componentData{}),
+		}, {
+			name:    "err missing param",
+			fn:      func(d componentData) {},
+			params:  []string{"pollID"},
+			wantErr: regexp.MustCompile(`^analyzing component data\(.*?\): field "Choice" named "choice" has no matching parameter`),
+		}, {
+			name: "err slice field",
+			fn: func(d struct {
+				Tags []string `diskoi:"name:tag,max:2"`
+			}) {
+			},
+			params:  []string{"tag"},
+			wantErr: regexp.MustCompile(`^analyzing component data\(.*?\): slice field "Tags" is unsupported in component data$`),
+		}, {
+			name:    "err data struct out of order",
+			fn:      func(d componentData, s *discordgo.Session) {},
+			wantErr: regexp.MustCompile(`^unrecognized argument .*?\(#0\) on function, should be`),
+		}, {
+			name:    "err data not struct",
+			fn:      func(c complex64) {},
+			wantErr: regexp.MustCompile(`^unrecognized data struct argument`),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+			args, dType, _, err := analyzeComponentFn(tc.fn, tc.params)
+			if tc.wantErr != nil {
+				r.Regexp(tc.wantErr, err)
+			} else {
+				r.Nil(err)
+			}
+			r.Equal(tc.wantType, dType)
+			if len(tc.wantArg) != 0 {
+				r.Equal(len(tc.wantArg), len(args), "results and want argument length should be equal")
+				for i, arg := range tc.wantArg {
+					r.Equal(arg.typ, args[i].typ, fmt.Sprintf("expected fnArgumentType to be the same on: #%d", i))
+					r.Equal(arg.reflectTyp, args[i].reflectTyp, fmt.Sprintf("expected reflect type to be the same on: #%d", i))
+				}
+			} else {
+				r.Empty(args)
+			}
+		})
+	}
+}
+
 func TestAnalyzeFunctionArgument(t *testing.T) {
 	cases := []struct {
 		name     string
@@ -540,6 +675,61 @@ func TestAnalyzeCommandArgumentField(t *testing.T) {
 			in:           reflect.StructField{Tag: `diskoi:"name:foo"`, Type: reflect.TypeOf((*commandArgument)(nil))},
 			wantErr:      true,
 			wantErrRegex: regexp.MustCompile("^unrecognized struct \".*?\""),
+		}, {
+			name: "test numeric constraints",
+			in:   reflect.StructField{Name: "test", Tag: `diskoi:"min:-5,max:120"`, Type: reflect.TypeOf(int8(0))},
+			cmd: &commandArgument{
+				fieldName: "test",
+				Name:      "test",
+				cType:     discordgo.ApplicationCommandOptionInteger,
+				MinValue:  floatPtr(-5),
+				MaxValue:  floatPtr(120),
+			},
+		}, {
+			name: "test length constraints",
+			in:   reflect.StructField{Name: "test", Tag: `diskoi:"min_length:1,max_length:100"`, Type: reflect.TypeOf("")},
+			cmd: &commandArgument{
+				fieldName: "test",
+				Name:      "test",
+				cType:     discordgo.ApplicationCommandOptionString,
+				MinLength: intPtr(1),
+				MaxLength: intPtr(100),
+			},
+		}, {
+			name:         "test negative min on unsigned",
+			in:           reflect.StructField{Tag: `diskoi:"min:-1"`, Type: reflect.TypeOf(uint8(0))},
+			wantErr:      true,
+			wantErrRegex: regexp.MustCompile(`^tag "min": converting "-1" into uint: `),
+		}, {
+			name:         "test max overflow",
+			in:           reflect.StructField{Tag: `diskoi:"max:256"`, Type: reflect.TypeOf(uint8(0))},
+			wantErr:      true,
+			wantErrRegex: regexp.MustCompile(`^tag "max": "256" overflows uint8$`),
+		}, {
+			name:         "test min larger than max",
+			in:           reflect.StructField{Tag: `diskoi:"min:2.5,max:1"`, Type: reflect.TypeOf(float64(0))},
+			wantErr:      true,
+			wantErrRegex: regexp.MustCompile(`^min "2.5" is larger than max "1"$`),
+		}, {
+			name:         "test min on string",
+			in:           reflect.StructField{Tag: `diskoi:"min:1"`, Type: reflect.TypeOf("")},
+			wantErr:      true,
+			wantErrRegex: regexp.MustCompile(`^tag "min" is only supported on integer and number fields, not "string"$`),
+		}, {
+			name:         "test max of zero",
+			in:           reflect.StructField{Tag: `diskoi:"min:-5,max:0"`, Type: reflect.TypeOf(0)},
+			wantErr:      true,
+			wantErrRegex: regexp.MustCompile(`^tag "max": 0 is not supported, discordgo omits a max value of 0$`),
+		}, {
+			name:         "test length on int",
+			in:           reflect.StructField{Tag: `diskoi:"max_length:1"`, Type: reflect.TypeOf(0)},
+			wantErr:      true,
+			wantErrRegex: regexp.MustCompile(`^tag "max_length" is only supported on string fields, not "int"$`),
+		}, {
+			name:         "test length out of range",
+			in:           reflect.StructField{Tag: `diskoi:"max_length:6001"`, Type: reflect.TypeOf("")},
+			wantErr:      true,
+			wantErrRegex: regexp.MustCompile(`^tag "max_length": "6001" out of range, expecting 0 to 6000$`),
 		}, {
 			name:         "test unsupported kind",
 			in:           reflect.StructField{Tag: `diskoi:"name:foo"`, Type: reflect.TypeOf((complex)(0, 0))},
@@ -611,3 +801,260 @@ type EmbeddableTest struct {
 	Test2 int
 	Embeddable1
 }
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestAnalyzeArgumentDefault(t *testing.T) {
+	cases := []struct {
+		name    string
+		in      reflect.StructField
+		want    interface{}
+		wantErr *regexp.Regexp
+	}{
+		{
+			name: "string",
+			in:   reflect.StructField{Name: "test", Tag: `diskoi:"default:foo bar"`, Type: reflect.TypeOf((*string)(nil))},
+			want: "foo bar",
+		}, {
+			name: "empty string",
+			in:   reflect.StructField{Name: "test", Tag: `diskoi:"default:"`, Type: reflect.TypeOf("")},
+			want: "",
+		}, {
+			name: "int8",
+			in:   reflect.StructField{Name: "test", Tag: `diskoi:"default:-5"`, Type: reflect.TypeOf(int8(0))},
+			want: int8(-5),
+		}, {
+			name: "bool",
+			in:   reflect.StructField{Name: "test", Tag: `diskoi:"default:true"`, Type: reflect.TypeOf(false)},
+			want: true,
+		}, {
+			name: "float32",
+			in:   reflect.StructField{Name: "test", Tag: `diskoi:"default:1.5"`, Type: reflect.TypeOf(float32(0))},
+			want: float32(1.5),
+		}, {
+			name:    "overflow",
+			in:      reflect.StructField{Name: "test", Tag: `diskoi:"default:256"`, Type: reflect.TypeOf(uint8(0))},
+			wantErr: regexp.MustCompile(`^tag "default": converting "256" into uint8: `),
+		}, {
+			name:    "wrong kind",
+			in:      reflect.StructField{Name: "test", Tag: `diskoi:"default:foo"`, Type: reflect.TypeOf(0)},
+			wantErr: regexp.MustCompile(`^tag "default": converting "foo" into int: `),
+		}, {
+			name:    "out of range",
+			in:      reflect.StructField{Name: "test", Tag: `diskoi:"default:11,max:10"`, Type: reflect.TypeOf(0)},
+			wantErr: regexp.MustCompile(`^default "11" is out of range of field "test"$`),
+		}, {
+			name:    "required",
+			in:      reflect.StructField{Name: "test", Tag: `diskoi:"default:1,required"`, Type: reflect.TypeOf(0)},
+			wantErr: regexp.MustCompile(`^default cant be set on required field "test"$`),
+		}, {
+			name:    "struct",
+			in:      reflect.StructField{Name: "test", Tag: `diskoi:"default:1"`, Type: reflect.TypeOf((*discordgo.User)(nil))},
+			wantErr: regexp.MustCompile(`^tag "default": unsupported on "discordgo.User"$`),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+			arg, err := analyzeCommandArgumentField(tc.in)
+			if tc.wantErr != nil {
+				r.Regexp(tc.wantErr, err)
+				return
+			}
+			r.Nil(err)
+			r.Equal(tc.want, arg.defaultValue.Interface())
+		})
+	}
+}
+
+type testPriority int
+
+func (p testPriority) String() string {
+	return [...]string{"low", "high"}[p]
+}
+
+func (p testPriority) DiskoiEnumValues() []fmt.Stringer {
+	return []fmt.Stringer{testPriority(0), testPriority(1)}
+}
+
+type testColor string
+
+func (c *testColor) String() string {
+	return strings.ToUpper(string(*c))
+}
+
+func (c *testColor) DiskoiEnumValues() []fmt.Stringer {
+	red, blue := testColor("red"), testColor("blue")
+	return []fmt.Stringer{&red, &blue}
+}
+
+type testBadEnum int
+
+func (testBadEnum) String() string {
+	return "bad"
+}
+
+func (testBadEnum) DiskoiEnumValues() []fmt.Stringer {
+	return []fmt.Stringer{testPriority(0)}
+}
+
+func TestAnalyzeArgumentChoices(t *testing.T) {
+	cases := []struct {
+		name    string
+		in      reflect.StructField
+		want    []*discordgo.ApplicationCommandOptionChoice
+		wantErr *regexp.Regexp
+	}{
+		{
+			name: "tag",
+			in:   reflect.StructField{Name: "test", Tag: `diskoi:"choices:low=1|medium=2|high=3"`, Type: reflect.TypeOf(uint8(0))},
+			want: []*discordgo.ApplicationCommandOptionChoice{
+				{Name: "low", Value: uint64(1)}, {Name: "medium", Value: uint64(2)}, {Name: "high", Value: uint64(3)},
+			},
+		}, {
+			name: "tag without values",
+			in:   reflect.StructField{Name: "test", Tag: `diskoi:"choices:red|blue"`, Type: reflect.TypeOf((*string)(nil))},
+			want: []*discordgo.ApplicationCommandOptionChoice{{Name: "red", Value: "red"}, {Name: "blue", Value: "blue"}},
+		}, {
+			name: "enum",
+			in:   reflect.StructField{Name: "test", Type: reflect.TypeOf(testPriority(0))},
+			want: []*discordgo.ApplicationCommandOptionChoice{{Name: "low", Value: int64(0)}, {Name: "high", Value: int64(1)}},
+		}, {
+			name: "pointer enum",
+			in:   reflect.StructField{Name: "test", Type: reflect.TypeOf((*testColor)(nil))},
+			want: []*discordgo.ApplicationCommandOptionChoice{{Name: "RED", Value: "red"}, {Name: "BLUE", Value: "blue"}},
+		}, {
+			name: "tag over enum",
+			in:   reflect.StructField{Name: "test", Tag: `diskoi:"choices:only=1"`, Type: reflect.TypeOf(testPriority(0))},
+			want: []*discordgo.ApplicationCommandOptionChoice{{Name: "only", Value: int64(1)}},
+		}, {
+			name:    "bad value",
+			in:      reflect.StructField{Name: "test", Tag: `diskoi:"choices:low=a"`, Type: reflect.TypeOf(0)},
+			wantErr: regexp.MustCompile(`^tag "choices": choice "low": converting "a" into int: `),
+		}, {
+			name:    "empty name",
+			in:      reflect.StructField{Name: "test", Tag: `diskoi:"choices:=1"`, Type: reflect.TypeOf(0)},
+			wantErr: regexp.MustCompile(`^choice name "" should be 1 to 100 characters long$`),
+		}, {
+			name:    "bool",
+			in:      reflect.StructField{Name: "test", Tag: `diskoi:"choices:yes=true"`, Type: reflect.TypeOf(false)},
+			wantErr: regexp.MustCompile(`^choices are only supported on string, integer and number fields, not "bool"$`),
+		}, {
+			name:    "enum of another type",
+			in:      reflect.StructField{Name: "test", Type: reflect.TypeOf(testBadEnum(0))},
+			wantErr: regexp.MustCompile(`^enum value "low" is type of diskoi.testPriority, expecting diskoi.testBadEnum$`),
+		}, {
+			name:    "default outside choices",
+			in:      reflect.StructField{Name: "test", Tag: `diskoi:"choices:a|b,default:c"`, Type: reflect.TypeOf("")},
+			wantErr: regexp.MustCompile(`^default "c" is not one of the choices of field "test"$`),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+			arg, err := analyzeCommandArgumentField(tc.in)
+			if tc.wantErr != nil {
+				r.Regexp(tc.wantErr, err)
+				return
+			}
+			r.Nil(err)
+			r.Equal(tc.want, arg.Choices)
+		})
+	}
+}
+
+type testRangeGroup struct {
+	Start int `diskoi:"required"`
+	End   int
+}
+
+func TestAnalyzeOptionGroup(t *testing.T) {
+	r := require.New(t)
+	type args struct {
+		Range  testRangeGroup
+		Window struct {
+			Range testRangeGroup `diskoi:"prefix:r"`
+			Label string
+		} `diskoi:"prefix:"`
+		User discordgo.User
+	}
+	e, err := NewExecutor("test", "", func(args) {})
+	r.Nil(err)
+	names := make([]string, 0, len(e.cmdArg))
+	for _, arg := range e.cmdArg {
+		names = append(names, arg.Name)
+	}
+	r.Equal([]string{"range_start", "range_end", "rstart", "rend", "label", "user"}, names)
+	r.Nil(e.SetDescription("Window.Range.End", "end of the window"))
+	r.Equal("end of the window", e.cmdArg[3].Description)
+
+	v, err := reconstructCommandArgument(e.cmdStruct, e.cmdArg, &Request{opts: []*discordgo.ApplicationCommandInteractionDataOption{
+		{Name: "range_start", Type: discordgo.ApplicationCommandOptionInteger, Value: float64(1)},
+		{Name: "rend", Type: discordgo.ApplicationCommandOptionInteger, Value: float64(2)},
+	}})
+	r.Nil(err)
+	want := args{Range: testRangeGroup{Start: 1}}
+	want.Window.Range.End = 2
+	r.Equal(want, v.Interface())
+
+	_, err = analyzeCommandStruct(reflect.TypeOf(struct {
+		Range testRangeGroup `diskoi:"name:foo"`
+	}{}), nil)
+	r.Regexp(`unrecognized tag "name" with value "foo" on option group$`, err)
+}
+
+func TestOptionOrder(t *testing.T) {
+	r := require.New(t)
+	type args struct {
+		Reason  string               `diskoi:"description:Reason"`
+		User    *discordgo.User      `diskoi:"required,description:User"`
+		Days    int                  `diskoi:"description:Days"`
+		Channel []*discordgo.Channel `diskoi:"max:3,min:1,description:Channels"`
+		Silent  bool                 `diskoi:"order:-1,description:Silent"`
+		Notify  bool                 `diskoi:"order:1,required,description:Notify"`
+	}
+	e := MustNewExecutor("ban", "Ban a user", func(args) {})
+	names := func() []string {
+		o := make([]string, 0, len(e.cmdArg))
+		for _, opt := range e.applicationCommand(nil).Options {
+			o = append(o, opt.Name)
+		}
+		return o
+	}
+	r.Equal([]string{"user", "channel1", "notify", "silent", "reason", "days", "channel2", "channel3"}, names())
+	r.Nil(e.SetOrder("Silent", 0))
+	r.Nil(e.SetOrder("Days", -2))
+	r.Equal([]string{"user", "channel1", "notify", "days", "reason", "channel2", "channel3", "silent"}, names())
+	r.Nil(e.Validate())
+
+	_, err := analyzeCommandArgumentField(reflect.StructField{Name: "test", Tag: `diskoi:"order:first"`, Type: reflect.TypeOf("")})
+	r.Regexp(`^tag "order": converting "first" into int: `, err)
+}
+
+func TestSingularName(t *testing.T) {
+	r := require.New(t)
+	for in, want := range map[string]string{
+		"users":       "user",
+		"target_tags": "target_tag",
+		"roles":       "role",
+		"status":      "status",
+		"address":     "address",
+		"analysis":    "analysis",
+		"boxes":       "boxes",
+		"categories":  "categories",
+		"matches":     "matches",
+		"s":           "s",
+		"channel":     "channel",
+	} {
+		r.Equal(want, singularName(in), in)
+	}
+	r.Equal("statuses", optionName(NamingLower, "Statuses", true))
+	r.Equal("status", optionName(NamingLower, "Status", true))
+	r.Equal("extra-user", optionName(NamingKebabCase, "ExtraUsers", true))
+}
@@ -1,15 +1,17 @@
 package diskoi
 
 import (
-	"context"
 	"fmt"
 	"github.com/bwmarrin/discordgo"
+	"math"
 	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 )
 
-func reconstructFunctionArgs(fnArg []*fnArgument, cmdArg []*commandArgument, data *MetaArgument, ctx context.Context,
-	s *discordgo.Session, i *discordgo.InteractionCreate,
-	o []*discordgo.ApplicationCommandInteractionDataOption) ([]reflect.Value, error) {
+func reconstructFunctionArgs(fnArg []*fnArgument, cmdArg []*commandArgument, r *Request) ([]reflect.Value, error) {
+	s, i, o := r.ses, r.ic, r.opts
 	values := make([]reflect.Value, 0, len(fnArg))
 	for _, arg := range fnArg {
 		switch arg.typ {
@@ -18,15 +20,18 @@ func reconstructFunctionArgs(fnArg []*fnArgument, cmdArg []*commandArgument, dat
 		case fnArgumentTypeInteraction:
 			values = append(values, reflect.ValueOf(i))
 		case fnArgumentTypeData:
-			v, err := reconstructCommandArgument(arg.reflectTyp, cmdArg, s, i, o)
+			v, err := reconstructCommandArgument(arg.reflectTyp, cmdArg, r)
 			if err != nil {
 				return nil, fmt.Errorf(`reconstructing command data "%s": %w`, arg.reflectTyp.String(), err)
 			}
+			if arg.ptr {
+				v = v.Addr()
+			}
 			values = append(values, v)
 		case fnArgumentTypeMeta:
-			values = append(values, reflect.ValueOf(data))
+			values = append(values, reflect.ValueOf(r.meta))
 		case fnArgumentTypeContext:
-			values = append(values, reflect.ValueOf(ctx))
+			values = append(values, reflect.ValueOf(r.ctx))
 		case fnArgumentTypeMarshal, fnArgumentTypeMarshalPtr:
 			mt := reflect.New(arg.reflectTyp)
 			m := mt.Interface().(Unmarshal)
@@ -39,6 +44,24 @@ func reconstructFunctionArgs(fnArg []*fnArgument, cmdArg []*commandArgument, dat
 			} else {
 				values = append(values, reflect.ValueOf(m).Elem())
 			}
+		case fnArgumentTypeTargetUser, fnArgumentTypeTargetMember, fnArgumentTypeTargetMessage:
+			v, err := reconstructTarget(arg.typ, resolver{s: s, i: i, fallback: r.fallback})
+			if err != nil {
+				return nil, fmt.Errorf("reconstructing command target: %w", err)
+			}
+			values = append(values, v)
+		case fnArgumentTypeComponentData:
+			cd, ok := i.Data.(discordgo.MessageComponentInteractionData)
+			if !ok {
+				return nil, newDiscordExpectationError("given interaction data is not MessageComponentInteractionData")
+			}
+			values = append(values, reflect.ValueOf(cd))
+		case fnArgumentTypeModalData:
+			md, ok := i.Data.(discordgo.ModalSubmitInteractionData)
+			if !ok {
+				return nil, newDiscordExpectationError("given interaction data is not ModalSubmitInteractionData")
+			}
+			values = append(values, reflect.ValueOf(md))
 		default:
 			return nil, fmt.Errorf("unrecognized argument type #%d (%s)", uint(arg.typ), arg.typ.String())
 		}
@@ -46,14 +69,132 @@ func reconstructFunctionArgs(fnArg []*fnArgument, cmdArg []*commandArgument, dat
 	return values, nil
 }
 
-func reconstructAutocompleteArgs(cmdArg []*commandArgument, data *MetaArgument,
-	s *discordgo.Session, i *discordgo.InteractionCreate,
-	opts []*discordgo.ApplicationCommandInteractionDataOption) (*commandArgument, []reflect.Value, error) {
-	for _, opt := range opts {
+//validateFunctionArgs calls the Validator of the reconstructed command structs, if they implement it
+func validateFunctionArgs(fnArg []*fnArgument, values []reflect.Value, r *Request) error {
+	for n, arg := range fnArg {
+		if arg.typ != fnArgumentTypeData {
+			continue
+		}
+		v := values[n]
+		var vd Validator
+		switch {
+		case v.Type().Implements(rTypeIValidator):
+			vd = v.Interface().(Validator)
+		case v.CanAddr() && v.Addr().Type().Implements(rTypeIValidator):
+			vd = v.Addr().Interface().(Validator)
+		default:
+			continue
+		}
+		if err := vd.Validate(*r); err != nil {
+			return CommandValidationError{name: arg.reflectTyp.String(), err: err}
+		}
+	}
+	return nil
+}
+
+//reconstructTarget resolves the target of a context menu command, users and members are looked up the same way as options
+//the member target will be nil if the command is not invoked within a guild
+func reconstructTarget(typ fnArgumentType, res resolver) (reflect.Value, error) {
+	id, ok := res.i.Data.(discordgo.ApplicationCommandInteractionData)
+	if !ok {
+		return reflect.Value{}, newDiscordExpectationError("given interaction data is not ApplicationCommandInteractionData")
+	}
+	switch typ {
+	case fnArgumentTypeTargetUser:
+		u, err := res.user(id.TargetID)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(u), nil
+	case fnArgumentTypeTargetMember:
+		m, err := res.member(id.TargetID)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(m), nil
+	case fnArgumentTypeTargetMessage:
+		if id.Resolved == nil {
+			return reflect.Value{}, newDiscordExpectationError(fmt.Sprintf(`missing resolved data for target "%s"`, id.TargetID))
+		}
+		msg, ok := id.Resolved.Messages[id.TargetID]
+		if !ok {
+			return reflect.Value{}, newDiscordExpectationError(fmt.Sprintf(`missing resolved message for target "%s"`, id.TargetID))
+		}
+		return reflect.ValueOf(msg), nil
+	default:
+		return reflect.Value{}, fmt.Errorf("unrecognized target argument type #%d (%s)", uint(typ), typ.String())
+	}
+}
+
+//reconstructStringOptions converts raw string values, such as custom id parameters or modal inputs, into options
+//so the data struct can be reconstructed with reconstructCommandArgument
+func reconstructStringOptions(cmdArg []*commandArgument, params map[string]string) ([]*discordgo.ApplicationCommandInteractionDataOption, error) {
+	opts := make([]*discordgo.ApplicationCommandInteractionDataOption, 0, len(cmdArg))
+	for _, arg := range cmdArg {
+		raw, ok := params[arg.Name]
+		if !ok {
+			continue
+		}
+		opt := &discordgo.ApplicationCommandInteractionDataOption{
+			Name: arg.Name,
+			Type: arg.cType,
+		}
+		switch arg.cType {
+		case discordgo.ApplicationCommandOptionInteger:
+			//integers keep the raw string, so they are parsed into the kind of the field without going through float64
+			//unless the field unmarshals the option itself, which expects the value discord would send
+			opt.Value = raw
+			if arg.unmarshal {
+				x, err := strconv.ParseInt(raw, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf(`converting parameter "%s" into integer: %w`, arg.Name, err)
+				}
+				opt.Value = float64(x)
+			}
+		case applicationCommandOptionDouble:
+			x, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				return nil, fmt.Errorf(`converting parameter "%s" into float: %w`, arg.Name, err)
+			}
+			opt.Value = x
+		case discordgo.ApplicationCommandOptionBoolean:
+			x, err := strconv.ParseBool(raw)
+			if err != nil {
+				return nil, fmt.Errorf(`converting parameter "%s" into bool: %w`, arg.Name, err)
+			}
+			opt.Value = x
+		default:
+			opt.Value = raw
+		}
+		opts = append(opts, opt)
+	}
+	return opts, nil
+}
+
+//reconstructModalValues collects the values of every non-empty text input in the submitted modal by custom id
+func reconstructModalValues(components []discordgo.MessageComponent) map[string]string {
+	values := make(map[string]string)
+	for _, c := range components {
+		switch v := c.(type) {
+		case *discordgo.ActionsRow:
+			for k, val := range reconstructModalValues(v.Components) {
+				values[k] = val
+			}
+		case *discordgo.TextInput:
+			if len(v.Value) > 0 {
+				values[v.CustomID] = v.Value
+			}
+		}
+	}
+	return values
+}
+
+func reconstructAutocompleteArgs(cmdArg []*commandArgument, r *Request) (*commandArgument, []reflect.Value, error) {
+	for _, opt := range r.opts {
 		if !opt.Focused {
 			continue
 		}
-		arg := findCmdArg(cmdArg, opt.Name)
+		arg, _ := findCmdArg(cmdArg, opt.Name)
 		if arg == nil {
 			return nil, nil, fmt.Errorf(`cant find option named "%s" type of "%v" locally`, opt.Name, opt.Type)
 		}
@@ -62,7 +203,7 @@ func reconstructAutocompleteArgs(cmdArg []*commandArgument, data *MetaArgument,
 				arg.fieldName, arg.cType, opt.Type))
 		}
 
-		values, err := reconstructFunctionArgs(arg.autocompleteArgs, cmdArg, data, context.Background(), s, i, opts)
+		values, err := reconstructFunctionArgs(arg.autocompleteArgs, cmdArg, r)
 		if err != nil {
 			return nil, nil, fmt.Errorf("reconstructing autocomplete: %w", err)
 		}
@@ -71,16 +212,20 @@ func reconstructAutocompleteArgs(cmdArg []*commandArgument, data *MetaArgument,
 	return nil, nil, newDiscordExpectationError(fmt.Sprintf("no options in focus"))
 }
 
-func reconstructCommandArgument(cmdStruct reflect.Type, cmdArg []*commandArgument,
-	s *discordgo.Session, i *discordgo.InteractionCreate,
-	opts []*discordgo.ApplicationCommandInteractionDataOption) (reflect.Value, error) {
+//reconstructCommandArgument reconstructs the command struct from the options
+//users, roles and channels are read from the resolved data of the interaction, and looked up as the fallback decides otherwise
+func reconstructCommandArgument(cmdStruct reflect.Type, cmdArg []*commandArgument, r *Request) (reflect.Value, error) {
+	s, i, opts := r.ses, r.ic, r.opts
 	val := reflect.New(cmdStruct)
 	if cmdStruct.Kind() != reflect.Ptr {
 		val = val.Elem()
 	}
+	res := resolver{s: s, i: i, fallback: r.fallback}
+	given := make(map[string]struct{}, len(opts))
+	elems := sliceElements{}
 
 	for _, opt := range opts {
-		py := findCmdArg(cmdArg, opt.Name)
+		py, n := findCmdArg(cmdArg, opt.Name)
 		if py == nil {
 			return reflect.Value{}, fmt.Errorf(`cant find option named "%s" type of "%v" locally`, opt.Name, opt.Type)
 		}
@@ -88,14 +233,65 @@ func reconstructCommandArgument(cmdStruct reflect.Type, cmdArg []*commandArgumen
 			return reflect.Value{}, newDiscordExpectationError(fmt.Sprintf(`option type mismatch in "%s": we expect it to be "%v", but discord says it is "%v"`,
 				py.fieldName, py.cType, opt.Type))
 		}
+		given[py.Name] = struct{}{}
 		fVal := val.FieldByIndex(py.fieldIndex)
+		if py.count > 0 {
+			//elements of slice fields are reconstructed on their own, and collected once every option is read
+			fVal = reflect.New(fVal.Type().Elem()).Elem()
+		}
+		fTyp := fVal.Type()
+		if fTyp.Kind() == reflect.Ptr {
+			fTyp = fTyp.Elem()
+		}
+		//focused options are partially typed values of autocomplete, so they aren't checked
+		if !opt.Focused {
+			if err := checkArgumentBounds(py, fTyp, opt); err != nil {
+				return reflect.Value{}, err
+			}
+		}
+		if py.converter != nil {
+			//focused options are partially typed, so they are left empty instead of failing the autocomplete
+			if opt.Focused {
+				continue
+			}
+			cv, err := convertOption(py, opt.StringValue())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			if fVal.Type() != cv.Type() {
+				ptr := reflect.New(cv.Type())
+				ptr.Elem().Set(cv)
+				cv = ptr
+			}
+			fVal.Set(cv)
+			elems.set(py, n, fVal)
+			continue
+		}
+		if py.unmarshal {
+			if opt.Focused {
+				continue
+			}
+			if err := unmarshalOption(fVal, opt, r); err != nil {
+				return reflect.Value{}, fmt.Errorf(`unmarshalling "%s": %w`, py.fieldName, err)
+			}
+			elems.set(py, n, fVal)
+			continue
+		}
 		var v interface{}
 		switch opt.Type {
 		case discordgo.ApplicationCommandOptionString:
 			x := opt.StringValue()
 			v = &x
 		case discordgo.ApplicationCommandOptionInteger:
-			switch fVal.Kind() {
+			if raw, ok := opt.Value.(string); ok {
+				x, err := parseIntegerParameter(py, fTyp, raw)
+				if err != nil {
+					return reflect.Value{}, err
+				}
+				v = x
+				break
+			}
+			switch fTyp.Kind() {
 			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 				x := opt.UintValue()
 				v = &x
@@ -106,48 +302,270 @@ func reconstructCommandArgument(cmdStruct reflect.Type, cmdArg []*commandArgumen
 		case discordgo.ApplicationCommandOptionBoolean:
 			x := opt.BoolValue()
 			v = &x
-		case 10: //type doubles
+		case applicationCommandOptionDouble:
 			x := opt.FloatValue()
 			v = &x
-		case discordgo.ApplicationCommandOptionChannel:
-			v = opt.ChannelValue(s)
-		case discordgo.ApplicationCommandOptionUser:
-			v = opt.UserValue(s)
-		case discordgo.ApplicationCommandOptionRole:
-			v = opt.RoleValue(s, i.GuildID)
-		case discordgo.ApplicationCommandOptionMentionable:
-			men := &Mentionable{}
-			if u, err := s.User(opt.Value.(string)); err == nil {
-				men.Value = u
-			} else if r, err := s.State.Role(i.GuildID, opt.Value.(string)); err == nil {
-				men.Value = r
-			}
-			v = men
+		case discordgo.ApplicationCommandOptionChannel, discordgo.ApplicationCommandOptionUser,
+			discordgo.ApplicationCommandOptionRole, discordgo.ApplicationCommandOptionMentionable:
+			id, ok := opt.Value.(string)
+			if !ok {
+				return reflect.Value{}, newDiscordExpectationError(fmt.Sprintf(`value of "%s" is type of %T, expecting string`, py.fieldName, opt.Value))
+			}
+			var err error
+			switch opt.Type {
+			case discordgo.ApplicationCommandOptionChannel:
+				v, err = res.channel(id)
+			case discordgo.ApplicationCommandOptionUser:
+				if fTyp != rTypeMember.Elem() {
+					v, err = res.user(id)
+					break
+				}
+				var m *discordgo.Member
+				m, err = res.member(id)
+				if err == nil && m == nil {
+					//outside of guilds there's no member, so the field is left empty
+					continue
+				}
+				v = m
+			case discordgo.ApplicationCommandOptionRole:
+				v, err = res.role(id)
+			default:
+				v, err = res.mentionable(id)
+			}
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf(`resolving "%s": %w`, py.fieldName, err)
+			}
+		case discordgo.ApplicationCommandOptionAttachment:
+			att, err := resolveAttachment(i, opt)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			if fTyp == reflect.TypeOf(Attachment{}) {
+				a := &Attachment{MessageAttachment: att}
+				if s != nil {
+					a.client = s.Client
+				}
+				v = a
+			} else {
+				v = att
+			}
 		default:
 			return reflect.Value{}, newDiscordExpectationError(fmt.Sprintf(`unrecognized ApplicationCommandOptionType "%v" in "%s"`, opt.Type, py.fieldName))
 		}
-		recVal := reflect.ValueOf(v)
-		if fVal.Kind() != reflect.Ptr {
-			recVal = recVal.Elem()
-		}
-		if fVal.Kind() != recVal.Kind() {
-			if recVal.CanConvert(fVal.Type()) {
-				recVal = recVal.Convert(fVal.Type())
+		recVal := reflect.ValueOf(v).Elem()
+		if recVal.Type() != fTyp {
+			if recVal.CanConvert(fTyp) {
+				recVal = recVal.Convert(fTyp)
 			} else {
 				return reflect.Value{}, fmt.Errorf(`cant convert %s(%v) into %s(%v)`,
-					recVal.Type().String(), recVal.Type().Kind(), fVal.Type().String(), fVal.Type().Kind())
+					recVal.Type().String(), recVal.Type().Kind(), fTyp.String(), fTyp.Kind())
 			}
 		}
+		if fVal.Kind() == reflect.Ptr {
+			ptr := reflect.New(fTyp)
+			ptr.Elem().Set(recVal)
+			recVal = ptr
+		}
 		fVal.Set(recVal)
+		elems.set(py, n, fVal)
+	}
+	elems.apply(val)
+	for _, arg := range cmdArg {
+		if _, ok := given[arg.Name]; ok || !arg.defaultValue.IsValid() {
+			continue
+		}
+		fVal := val.FieldByIndex(arg.fieldIndex)
+		dv := arg.defaultValue
+		if fVal.Type() != dv.Type() {
+			ptr := reflect.New(dv.Type())
+			ptr.Elem().Set(dv)
+			dv = ptr
+		}
+		fVal.Set(dv)
 	}
 	return val, nil
 }
 
-func findCmdArg(cmdArgs []*commandArgument, name string) *commandArgument {
-	for _, arg := range cmdArgs {
-		if name == arg.Name {
-			return arg
+//unmarshalOption unmarshals the option into the field, pointer fields are allocated first
+func unmarshalOption(fVal reflect.Value, opt *discordgo.ApplicationCommandInteractionDataOption, r *Request) error {
+	if fVal.Kind() == reflect.Ptr {
+		ptr := reflect.New(fVal.Type().Elem())
+		if err := ptr.Interface().(OptionUnmarshal).UnmarshalDiskoiOption(opt, r); err != nil {
+			return err
 		}
+		fVal.Set(ptr)
+		return nil
+	}
+	return fVal.Addr().Interface().(OptionUnmarshal).UnmarshalDiskoiOption(opt, r)
+}
+
+//resolveAttachment finds the attachment of the option in the resolved data of the interaction
+func resolveAttachment(i *discordgo.InteractionCreate, opt *discordgo.ApplicationCommandInteractionDataOption) (*discordgo.MessageAttachment, error) {
+	id, ok := opt.Value.(string)
+	if !ok {
+		return nil, newDiscordExpectationError(fmt.Sprintf(`value of attachment "%s" is type of %T, expecting string`, opt.Name, opt.Value))
+	}
+	if i == nil {
+		return nil, newDiscordExpectationError(fmt.Sprintf(`missing interaction for attachment "%s"`, id))
+	}
+	data, ok := i.Data.(discordgo.ApplicationCommandInteractionData)
+	if !ok || data.Resolved == nil {
+		return nil, newDiscordExpectationError(fmt.Sprintf(`missing resolved data for attachment "%s"`, id))
+	}
+	att, ok := data.Resolved.Attachments[id]
+	if !ok {
+		return nil, newDiscordExpectationError(fmt.Sprintf(`missing resolved attachment "%s"`, id))
+	}
+	return att, nil
+}
+
+//checkArgumentBounds checks the value against the constraints of the argument and the kind of the field
+//discord validates the constraints, but a modified payload could still overflow the field
+func checkArgumentBounds(arg *commandArgument, typ reflect.Type, opt *discordgo.ApplicationCommandInteractionDataOption) error {
+	switch opt.Type {
+	case discordgo.ApplicationCommandOptionInteger, applicationCommandOptionDouble:
+		if raw, ok := opt.Value.(string); ok && opt.Type == discordgo.ApplicationCommandOptionInteger {
+			return checkIntegerParameterBounds(arg, typ, raw)
+		}
+		f, ok := opt.Value.(float64)
+		if !ok {
+			return newDiscordExpectationError(fmt.Sprintf(`value of "%s" is type of %T, expecting float64`, arg.fieldName, opt.Value))
+		}
+		if (arg.MinValue != nil && f < *arg.MinValue) || (arg.MaxValue != nil && f > *arg.MaxValue) {
+			return fmt.Errorf(`value "%v" of "%s" is out of range`, f, arg.fieldName)
+		}
+		if opt.Type == discordgo.ApplicationCommandOptionInteger && f != math.Trunc(f) {
+			return fmt.Errorf(`value "%v" of "%s" is not an integer`, f, arg.fieldName)
+		}
+		if numberOverflows(typ, f) {
+			return fmt.Errorf(`value "%v" of "%s" overflows %s`, f, arg.fieldName, typ.String())
+		}
+	case discordgo.ApplicationCommandOptionString:
+		s, ok := opt.Value.(string)
+		if !ok {
+			return newDiscordExpectationError(fmt.Sprintf(`value of "%s" is type of %T, expecting string`, arg.fieldName, opt.Value))
+		}
+		l := utf8.RuneCountInString(s)
+		if (arg.MinLength != nil && l < *arg.MinLength) || (arg.MaxLength != nil && l > *arg.MaxLength) {
+			return fmt.Errorf(`length "%d" of "%s" is out of range`, l, arg.fieldName)
+		}
+	}
+	if len(arg.Choices) > 0 && !choicesContain(arg.Choices, opt.Value) {
+		return fmt.Errorf(`value "%v" of "%s" is not one of the choices`, opt.Value, arg.fieldName)
 	}
 	return nil
 }
+
+//checkIntegerParameterBounds checks an integer given as a raw string, such as a custom id parameter
+func checkIntegerParameterBounds(arg *commandArgument, typ reflect.Type, raw string) error {
+	x, err := parseIntegerParameter(arg, typ, raw)
+	if err != nil {
+		return err
+	}
+	value := reflect.ValueOf(x).Elem()
+	f, _ := toFloat64(value)
+	if (arg.MinValue != nil && f < *arg.MinValue) || (arg.MaxValue != nil && f > *arg.MaxValue) {
+		return fmt.Errorf(`value "%s" of "%s" is out of range`, raw, arg.fieldName)
+	}
+	if len(arg.Choices) > 0 && !choicesContain(arg.Choices, value.Interface()) {
+		return fmt.Errorf(`value "%s" of "%s" is not one of the choices`, raw, arg.fieldName)
+	}
+	return nil
+}
+
+//parseIntegerParameter parses the raw integer into a pointer to int64 or uint64 by the kind of the field
+//so the full range of the field is kept, such as snowflakes that float64 cant represent
+func parseIntegerParameter(arg *commandArgument, typ reflect.Type, raw string) (interface{}, error) {
+	v := reflect.New(typ).Elem()
+	switch typ.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		x, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf(`converting parameter "%s" into integer: %w`, arg.Name, err)
+		}
+		if v.OverflowUint(x) {
+			return nil, fmt.Errorf(`value "%s" of "%s" overflows %s`, raw, arg.fieldName, typ.String())
+		}
+		return &x, nil
+	default:
+		x, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf(`converting parameter "%s" into integer: %w`, arg.Name, err)
+		}
+		if k := typ.Kind(); k >= reflect.Int && k <= reflect.Int64 && v.OverflowInt(x) {
+			return nil, fmt.Errorf(`value "%s" of "%s" overflows %s`, raw, arg.fieldName, typ.String())
+		}
+		return &x, nil
+	}
+}
+
+func choicesContain(choices []*discordgo.ApplicationCommandOptionChoice, value interface{}) bool {
+	for _, c := range choices {
+		if choiceValueEqual(c.Value, value) {
+			return true
+		}
+	}
+	return false
+}
+
+//numberOverflows checks if the number fits into the kind of typ, other kinds never overflow
+func numberOverflows(typ reflect.Type, f float64) bool {
+	v := reflect.New(typ).Elem()
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return f < math.MinInt64 || f >= math.MaxInt64 || v.OverflowInt(int64(f))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return f < 0 || f >= math.MaxUint64 || v.OverflowUint(uint64(f))
+	case reflect.Float32, reflect.Float64:
+		return v.OverflowFloat(f)
+	default:
+		return false
+	}
+}
+
+//findCmdArg finds the argument of the option by name, with the position of the element if it's a numbered option of a slice field
+func findCmdArg(cmdArgs []*commandArgument, name string) (*commandArgument, int) {
+	for _, arg := range cmdArgs {
+		if arg.count == 0 {
+			if name == arg.Name {
+				return arg, 0
+			}
+			continue
+		}
+		if !strings.HasPrefix(name, arg.Name) {
+			continue
+		}
+		n, err := strconv.Atoi(name[len(arg.Name):])
+		if err == nil && n >= 1 && n <= arg.count && name == arg.Name+strconv.Itoa(n) {
+			return arg, n - 1
+		}
+	}
+	return nil, 0
+}
+
+//sliceElements collects the elements of slice fields by position, as numbered options can come in any order
+type sliceElements map[*commandArgument][]reflect.Value
+
+func (e sliceElements) set(arg *commandArgument, n int, v reflect.Value) {
+	if arg.count == 0 {
+		return
+	}
+	if e[arg] == nil {
+		e[arg] = make([]reflect.Value, arg.count)
+	}
+	e[arg][n] = v
+}
+
+//apply sets the slice fields to the given elements in the order of their numbers, absent numbers are skipped
+func (e sliceElements) apply(val reflect.Value) {
+	for arg, values := range e {
+		fVal := val.FieldByIndex(arg.fieldIndex)
+		sl := reflect.MakeSlice(fVal.Type(), 0, len(values))
+		for _, v := range values {
+			if v.IsValid() {
+				sl = reflect.Append(sl, v)
+			}
+		}
+		fVal.Set(sl)
+	}
+}
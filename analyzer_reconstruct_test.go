@@ -1,10 +1,16 @@
 package diskoi
 
 import (
+	"context"
+	"errors"
 	"github.com/bwmarrin/discordgo"
 	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"regexp"
+	"strings"
 	"testing"
 )
 
@@ -95,6 +101,71 @@ func TestReconstructCommandArgument(t *testing.T) {
 				Name:       "string",
 			}},
 			wantErr: regexp.MustCompile(`unrecognized ApplicationCommandOptionType`),
+		}, {
+			name:      "bounds",
+			cmdStruct: reflect.TypeOf(ReconstructBounds{}),
+			opts: []*discordgo.ApplicationCommandInteractionDataOption{
+				{Name: "name", Type: discordgo.ApplicationCommandOptionString, Value: "ünï"},
+				{Name: "small", Type: discordgo.ApplicationCommandOptionInteger, Value: float64(255)},
+				{Name: "level", Type: discordgo.ApplicationCommandOptionInteger, Value: float64(10)},
+			},
+			want: reflect.ValueOf(ReconstructBounds{Name: "ünï", Small: 255, Level: 10}),
+		}, {
+			name:      "err out of range",
+			cmdStruct: reflect.TypeOf(ReconstructBounds{}),
+			opts: []*discordgo.ApplicationCommandInteractionDataOption{
+				{Name: "level", Type: discordgo.ApplicationCommandOptionInteger, Value: float64(11)},
+			},
+			wantErr: regexp.MustCompile(`^value "11" of "Level" is out of range$`),
+		}, {
+			name:      "err overflow",
+			cmdStruct: reflect.TypeOf(ReconstructBounds{}),
+			opts: []*discordgo.ApplicationCommandInteractionDataOption{
+				{Name: "small", Type: discordgo.ApplicationCommandOptionInteger, Value: float64(256)},
+			},
+			wantErr: regexp.MustCompile(`^value "256" of "Small" overflows uint8$`),
+		}, {
+			name:      "err negative unsigned",
+			cmdStruct: reflect.TypeOf(ReconstructBounds{}),
+			opts: []*discordgo.ApplicationCommandInteractionDataOption{
+				{Name: "small", Type: discordgo.ApplicationCommandOptionInteger, Value: float64(-1)},
+			},
+			wantErr: regexp.MustCompile(`^value "-1" of "Small" overflows uint8$`),
+		}, {
+			name:      "err fraction",
+			cmdStruct: reflect.TypeOf(ReconstructBounds{}),
+			opts: []*discordgo.ApplicationCommandInteractionDataOption{
+				{Name: "level", Type: discordgo.ApplicationCommandOptionInteger, Value: 2.5},
+			},
+			wantErr: regexp.MustCompile(`^value "2.5" of "Level" is not an integer$`),
+		}, {
+			name:      "err length",
+			cmdStruct: reflect.TypeOf(ReconstructBounds{}),
+			opts: []*discordgo.ApplicationCommandInteractionDataOption{
+				{Name: "name", Type: discordgo.ApplicationCommandOptionString, Value: "abcde"},
+			},
+			wantErr: regexp.MustCompile(`^length "5" of "Name" is out of range$`),
+		}, {
+			name:      "err not a choice",
+			cmdStruct: reflect.TypeOf(ReconstructChoices{}),
+			opts: []*discordgo.ApplicationCommandInteractionDataOption{
+				{Name: "level", Type: discordgo.ApplicationCommandOptionInteger, Value: float64(4)},
+			},
+			wantErr: regexp.MustCompile(`^value "4" of "Level" is not one of the choices$`),
+		}, {
+			name:      "choice",
+			cmdStruct: reflect.TypeOf(ReconstructChoices{}),
+			opts: []*discordgo.ApplicationCommandInteractionDataOption{
+				{Name: "level", Type: discordgo.ApplicationCommandOptionInteger, Value: float64(3)},
+			},
+			want: reflect.ValueOf(ReconstructChoices{Level: 3}),
+		}, {
+			name:      "focused is unchecked",
+			cmdStruct: reflect.TypeOf(ReconstructBounds{}),
+			opts: []*discordgo.ApplicationCommandInteractionDataOption{
+				{Name: "name", Type: discordgo.ApplicationCommandOptionString, Value: "a", Focused: true},
+			},
+			want: reflect.ValueOf(ReconstructBounds{Name: "a"}),
 		},
 	}
 	for _, tc := range cases {
@@ -108,7 +179,7 @@ func TestReconstructCommandArgument(t *testing.T) {
 				cmdArg, err = analyzeCommandStruct(tc.cmdStruct, nil)
 				r.Nil(err)
 			}
-			value, err := reconstructCommandArgument(tc.cmdStruct, cmdArg, nil, nil, tc.opts)
+			value, err := reconstructCommandArgument(tc.cmdStruct, cmdArg, &Request{fallback: ResolveFallbackState, opts: tc.opts})
 			if tc.wantErr != nil {
 				r.Regexp(tc.wantErr, err)
 				return
@@ -125,6 +196,104 @@ func TestReconstructCommandArgument(t *testing.T) {
 	}
 }
 
+func TestReconstructTarget(t *testing.T) {
+	user := &discordgo.User{ID: "1", Username: "foo"}
+	i := &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		GuildID: "10",
+		Data: discordgo.ApplicationCommandInteractionData{
+			TargetID: "1",
+			Resolved: &discordgo.ApplicationCommandInteractionDataResolved{
+				Users:   map[string]*discordgo.User{"1": user},
+				Members: map[string]*discordgo.Member{"1": {Nick: "bar"}},
+			},
+		},
+	}}
+	r := require.New(t)
+
+	res := resolver{i: i, fallback: ResolveFallbackNone}
+	v, err := reconstructTarget(fnArgumentTypeTargetUser, res)
+	r.Nil(err)
+	r.Equal(user, v.Interface())
+
+	v, err = reconstructTarget(fnArgumentTypeTargetMember, res)
+	r.Nil(err)
+	r.Equal(&discordgo.Member{GuildID: "10", Nick: "bar", User: user}, v.Interface())
+
+	_, err = reconstructTarget(fnArgumentTypeTargetMessage, res)
+	r.Regexp(`missing resolved message for target "1"`, err)
+
+	//members missing from the resolved data fall back to the state
+	s := discordgo.NewState()
+	r.Nil(s.GuildAdd(&discordgo.Guild{ID: "10"}))
+	r.Nil(s.MemberAdd(&discordgo.Member{GuildID: "10", Nick: "baz", User: user}))
+	i.Data.(discordgo.ApplicationCommandInteractionData).Resolved.Members = nil
+	v, err = reconstructTarget(fnArgumentTypeTargetMember, resolver{s: &discordgo.Session{State: s}, i: i})
+	r.Nil(err)
+	r.Equal("baz", v.Interface().(*discordgo.Member).Nick)
+	_, err = reconstructTarget(fnArgumentTypeTargetMember, res)
+	r.Regexp(`cant resolve member "1"$`, err)
+
+	i.GuildID = ""
+	v, err = reconstructTarget(fnArgumentTypeTargetMember, res)
+	r.Nil(err)
+	r.Nil(v.Interface())
+}
+
+func TestReconstructAttachment(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/file.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer srv.Close()
+	file := &discordgo.MessageAttachment{ID: "5", Filename: "file.txt", URL: srv.URL + "/file.txt"}
+	i := &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		Data: discordgo.ApplicationCommandInteractionData{
+			Resolved: &discordgo.ApplicationCommandInteractionDataResolved{
+				Attachments: map[string]*discordgo.MessageAttachment{"5": file},
+			},
+		},
+	}}
+	type upload struct {
+		Raw  *discordgo.MessageAttachment
+		File *Attachment
+	}
+	r := require.New(t)
+	cmdArg, err := analyzeCommandStruct(reflect.TypeOf(upload{}), nil)
+	r.Nil(err)
+	r.Equal(discordgo.ApplicationCommandOptionAttachment, cmdArg[0].cType)
+	r.Equal(discordgo.ApplicationCommandOptionAttachment, cmdArg[1].cType)
+
+	v, err := reconstructCommandArgument(reflect.TypeOf(upload{}), cmdArg, &Request{ses: &discordgo.Session{Client: srv.Client()}, ic: i, fallback: ResolveFallbackState,
+		opts: []*discordgo.ApplicationCommandInteractionDataOption{
+			{Name: "raw", Type: discordgo.ApplicationCommandOptionAttachment, Value: "5"},
+			{Name: "file", Type: discordgo.ApplicationCommandOptionAttachment, Value: "5"},
+		}})
+	r.Nil(err)
+	u := v.Interface().(upload)
+	r.Equal(file, u.Raw)
+	r.Equal(file, u.File.MessageAttachment)
+
+	body, err := u.File.Open(context.Background())
+	r.Nil(err)
+	b, err := ioutil.ReadAll(body)
+	r.Nil(err)
+	r.Nil(body.Close())
+	r.Equal("hello", string(b))
+
+	u.File.URL = srv.URL + "/missing.txt"
+	_, err = u.File.Open(context.Background())
+	r.Regexp(`downloading attachment "file.txt": unexpected status "404 Not Found"$`, err)
+
+	_, err = reconstructCommandArgument(reflect.TypeOf(upload{}), cmdArg, &Request{ic: i, fallback: ResolveFallbackState,
+		opts: []*discordgo.ApplicationCommandInteractionDataOption{
+			{Name: "raw", Type: discordgo.ApplicationCommandOptionAttachment, Value: "6"},
+		}})
+	r.Regexp(`missing resolved attachment "6"$`, err)
+}
+
 type Reconstruct1 struct {
 	String  string
 	Int64   int64
@@ -133,3 +302,223 @@ type Reconstruct1 struct {
 	Float64 float64
 	Float32 float32
 }
+
+type ReconstructChoices struct {
+	Level int `diskoi:"choices:low=1|medium=2|high=3"`
+}
+
+type ReconstructBounds struct {
+	Name  string `diskoi:"min_length:2,max_length:4"`
+	Small uint8
+	Level int `diskoi:"min:1,max:10"`
+}
+
+func TestReconstructDefault(t *testing.T) {
+	type args struct {
+		Name  *string `diskoi:"default:anon"`
+		Count int     `diskoi:"default:3"`
+		Limit *uint8
+	}
+	r := require.New(t)
+	e := MustNewExecutor("test", "test", func(args) {}).MustSetDefault("Limit", 10)
+	r.Regexp(`setting default of field "Limit": 300 cant be converted into uint8 without loss$`, e.SetDefault("Limit", 300))
+	r.Regexp(`setting default of field "Count": string cant be used as int$`, e.SetDefault("Count", "3"))
+	r.Regexp(`setting default of field "Limit": -1 cant be converted into uint8 without loss$`, e.SetDefault("Limit", -1))
+	r.Regexp(`setting default of field "Count": 9223372036854775808 cant be converted into int without loss$`, e.SetDefault("Count", uint64(1<<63)))
+	r.Regexp(`setting default of field "Limit": -2 cant be converted into uint8 without loss$`, e.SetDefault("Limit", -2.0))
+	r.Regexp(`required cant be set on field "Count" with a default$`, e.SetRequired("Count", true))
+	r.False(e.cmdArg[1].Required)
+
+	v, err := reconstructCommandArgument(e.cmdStruct, e.cmdArg, &Request{fallback: ResolveFallbackState})
+	r.Nil(err)
+	name, limit := "anon", uint8(10)
+	r.Equal(args{Name: &name, Count: 3, Limit: &limit}, v.Interface())
+
+	v, err = reconstructCommandArgument(e.cmdStruct, e.cmdArg, &Request{fallback: ResolveFallbackState,
+		opts: []*discordgo.ApplicationCommandInteractionDataOption{
+			{Name: "count", Type: discordgo.ApplicationCommandOptionInteger, Value: float64(0)},
+		}})
+	r.Nil(err)
+	r.Equal(0, v.Interface().(args).Count)
+
+	r.Nil(e.SetDefault("Limit", nil))
+	v, err = reconstructCommandArgument(e.cmdStruct, e.cmdArg, &Request{fallback: ResolveFallbackState})
+	r.Nil(err)
+	r.Nil(v.Interface().(args).Limit)
+}
+
+type testCSV []string
+
+func (c *testCSV) UnmarshalDiskoiOption(opt *discordgo.ApplicationCommandInteractionDataOption, r *Request) error {
+	if opt.StringValue() == "" {
+		return errors.New("empty list")
+	}
+	*c = strings.Split(opt.StringValue(), ",")
+	return nil
+}
+
+type testPercent float64
+
+func (p *testPercent) UnmarshalDiskoiOption(opt *discordgo.ApplicationCommandInteractionDataOption, r *Request) error {
+	*p = testPercent(opt.FloatValue() / 100)
+	return nil
+}
+
+func (testPercent) DiskoiOptionType() discordgo.ApplicationCommandOptionType {
+	return discordgo.ApplicationCommandOptionNumber
+}
+
+type testBadOptionType struct{}
+
+func (*testBadOptionType) UnmarshalDiskoiOption(*discordgo.ApplicationCommandInteractionDataOption, *Request) error {
+	return nil
+}
+
+func (*testBadOptionType) DiskoiOptionType() discordgo.ApplicationCommandOptionType {
+	return discordgo.ApplicationCommandOptionSubCommand
+}
+
+func TestReconstructOptionUnmarshal(t *testing.T) {
+	r := require.New(t)
+	type args struct {
+		List    testCSV
+		Percent *testPercent `diskoi:"max:100"`
+	}
+	cmdArg, err := analyzeCommandStruct(reflect.TypeOf(args{}), nil)
+	r.Nil(err)
+	r.Equal(discordgo.ApplicationCommandOptionString, cmdArg[0].cType)
+	r.Equal(discordgo.ApplicationCommandOptionNumber, cmdArg[1].cType)
+
+	v, err := reconstructCommandArgument(reflect.TypeOf(args{}), cmdArg, &Request{opts: []*discordgo.ApplicationCommandInteractionDataOption{
+		{Name: "list", Type: discordgo.ApplicationCommandOptionString, Value: "a,b"},
+		{Name: "percent", Type: discordgo.ApplicationCommandOptionNumber, Value: float64(50)},
+	}})
+	r.Nil(err)
+	half := testPercent(0.5)
+	r.Equal(args{List: testCSV{"a", "b"}, Percent: &half}, v.Interface())
+
+	_, err = reconstructCommandArgument(reflect.TypeOf(args{}), cmdArg, &Request{opts: []*discordgo.ApplicationCommandInteractionDataOption{
+		{Name: "list", Type: discordgo.ApplicationCommandOptionString, Value: ""},
+	}})
+	r.Regexp(`^unmarshalling "List": empty list$`, err)
+
+	_, err = reconstructCommandArgument(reflect.TypeOf(args{}), cmdArg, &Request{opts: []*discordgo.ApplicationCommandInteractionDataOption{
+		{Name: "percent", Type: discordgo.ApplicationCommandOptionNumber, Value: float64(101)},
+	}})
+	r.Regexp(`^value "101" of "Percent" is out of range$`, err)
+
+	_, err = analyzeCommandArgumentField(reflect.StructField{Name: "test", Type: reflect.TypeOf(testBadOptionType{})})
+	r.Regexp(`^unsupported option type "SubCommand" of "diskoi.testBadOptionType"$`, err)
+	_, err = analyzeCommandArgumentField(reflect.StructField{Name: "test", Tag: `diskoi:"default:a"`, Type: reflect.TypeOf(testCSV{})})
+	r.Regexp(`^default cant be set on field "test" implementing OptionUnmarshal$`, err)
+}
+
+type testRange struct {
+	Start int `diskoi:"required"`
+	End   int `diskoi:"required"`
+}
+
+func (t testRange) Validate(r Request) error {
+	if t.Start >= t.End {
+		return errors.New("start must be before end")
+	}
+	return nil
+}
+
+type testPtrRange struct {
+	Start int `diskoi:"required"`
+	End   int `diskoi:"required"`
+}
+
+func (t *testPtrRange) Validate(r Request) error {
+	return testRange(*t).Validate(r)
+}
+
+func TestValidateCommand(t *testing.T) {
+	r := require.New(t)
+	interaction := func(start, end float64) *discordgo.InteractionCreate {
+		return &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+			Type: discordgo.InteractionApplicationCommand,
+			Data: discordgo.ApplicationCommandInteractionData{Name: "range", Options: []*discordgo.ApplicationCommandInteractionDataOption{
+				{Name: "start", Type: discordgo.ApplicationCommandOptionInteger, Value: start},
+				{Name: "end", Type: discordgo.ApplicationCommandOptionInteger, Value: end},
+			}},
+		}}
+	}
+	for _, fn := range []interface{}{
+		func(d testRange) {},
+		func(d *testRange) {},
+		func(d testPtrRange) {},
+		func(d *testPtrRange) {},
+	} {
+		e, err := NewExecutor("range", "", fn)
+		r.Nil(err)
+		r.Nil(e.execute(nil, interaction(1, 2), Chain{}, ResolveFallbackState))
+
+		err = e.execute(nil, interaction(2, 1), Chain{}, ResolveFallbackState)
+		r.IsType(CommandValidationError{}, err)
+		r.Regexp(`^validating "diskoi.test(Ptr)?Range": start must be before end$`, err)
+		r.EqualError(err.(CommandValidationError).Reason(), "start must be before end")
+	}
+}
+
+func TestSliceOptions(t *testing.T) {
+	r := require.New(t)
+	type args struct {
+		Users []*discordgo.User `diskoi:"max:3,min:1,description:Users to ping"`
+		Tags  []string          `diskoi:"name:label,max:2,max_length:10"`
+		Count int
+	}
+	e, err := NewExecutor("ping", "", func(args) {})
+	r.Nil(err)
+	opts := e.applicationCommandOptions(nil, "ping")
+	r.Len(opts, 6)
+	names := make([]string, 0, len(opts))
+	for _, o := range opts {
+		names = append(names, o.Name)
+	}
+	r.Equal([]string{"user1", "user2", "user3", "label1", "label2", "count"}, names)
+	r.True(opts[0].Required)
+	r.False(opts[1].Required)
+	r.Equal("Users to ping", opts[2].Description)
+	r.Equal(discordgo.ApplicationCommandOptionUser, opts[2].Type)
+	r.Equal(10, opts[4].MaxLength)
+
+	u1, u3 := &discordgo.User{ID: "1"}, &discordgo.User{ID: "3"}
+	i := &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		Data: discordgo.ApplicationCommandInteractionData{Resolved: &discordgo.ApplicationCommandInteractionDataResolved{
+			Users: map[string]*discordgo.User{"1": u1, "3": u3},
+		}},
+	}}
+	v, err := reconstructCommandArgument(e.cmdStruct, e.cmdArg, &Request{ic: i, fallback: ResolveFallbackNone,
+		opts: []*discordgo.ApplicationCommandInteractionDataOption{
+			{Name: "user3", Type: discordgo.ApplicationCommandOptionUser, Value: "3"},
+			{Name: "label2", Type: discordgo.ApplicationCommandOptionString, Value: "b"},
+			{Name: "user1", Type: discordgo.ApplicationCommandOptionUser, Value: "1"},
+		}})
+	r.Nil(err)
+	r.Equal(args{Users: []*discordgo.User{u1, u3}, Tags: []string{"b"}}, v.Interface())
+
+	_, err = reconstructCommandArgument(e.cmdStruct, e.cmdArg, &Request{opts: []*discordgo.ApplicationCommandInteractionDataOption{
+		{Name: "label3", Type: discordgo.ApplicationCommandOptionString, Value: "c"},
+	}})
+	r.Regexp(`^cant find option named "label3"`, err)
+	_, err = reconstructCommandArgument(e.cmdStruct, e.cmdArg, &Request{opts: []*discordgo.ApplicationCommandInteractionDataOption{
+		{Name: "label1", Type: discordgo.ApplicationCommandOptionString, Value: "longer than ten"},
+	}})
+	r.Regexp(`^length "15" of "Tags" is out of range$`, err)
+
+	cases := []struct {
+		tag     string
+		wantErr string
+	}{
+		{``, `^slice fields require the tag "max" for the number of options$`},
+		{`diskoi:"max:26"`, `^tag "max": number of options should be 1 to 25, not 26$`},
+		{`diskoi:"max:2,min:3"`, `^tag "min": number of required options should be 0 to 2, not 3$`},
+		{`diskoi:"max:2,default:a"`, `^default cant be set on slice field "test"$`},
+	}
+	for _, tc := range cases {
+		_, err = analyzeCommandArgumentField(reflect.StructField{Name: "test", Tag: reflect.StructTag(tc.tag), Type: reflect.TypeOf([]string{})})
+		r.Regexp(tc.wantErr, err, tc.tag)
+	}
+}
@@ -4,12 +4,15 @@ import (
 	"fmt"
 	"github.com/bwmarrin/discordgo"
 	"reflect"
+	"strconv"
 	"strings"
 )
 
 type fnArgument struct {
 	typ        fnArgumentType
 	reflectTyp reflect.Type
+	//ptr is set when the data struct is taken as a pointer
+	ptr bool
 }
 
 type fnArgumentType uint8
@@ -23,6 +26,11 @@ const (
 	fnArgumentTypeContext
 	fnArgumentTypeMarshal
 	fnArgumentTypeMarshalPtr
+	fnArgumentTypeTargetUser
+	fnArgumentTypeTargetMember
+	fnArgumentTypeTargetMessage
+	fnArgumentTypeComponentData
+	fnArgumentTypeModalData
 )
 
 func (a fnArgumentType) String() string {
@@ -43,6 +51,16 @@ func (a fnArgumentType) String() string {
 		return "DiskoiMarshal"
 	case fnArgumentTypeMarshalPtr:
 		return "DiskoiMarshalPtr"
+	case fnArgumentTypeTargetUser:
+		return "Target User"
+	case fnArgumentTypeTargetMember:
+		return "Target Member"
+	case fnArgumentTypeTargetMessage:
+		return "Target Message"
+	case fnArgumentTypeComponentData:
+		return "Component Data"
+	case fnArgumentTypeModalData:
+		return "Modal Data"
 	default:
 		return fmt.Sprintf("fnArgumentType(%d)", a)
 	}
@@ -58,11 +76,125 @@ type commandArgument struct {
 	Required     bool
 	Choices      []*discordgo.ApplicationCommandOptionChoice
 	ChannelTypes []discordgo.ChannelType
+	MinValue     *float64
+	MaxValue     *float64
+	MinLength    *int
+	MaxLength    *int
+
+	//defaultValue is set into the field when the option is absent, it's invalid if there's no default
+	defaultValue reflect.Value
+
+	//converter parses string options into convertTyp, the field is either convertTyp or a pointer to it
+	converter  Converter
+	convertTyp reflect.Type
+	//unmarshal is set when the field implements OptionUnmarshal
+	unmarshal bool
+	//count is the number of numbered options a slice field is expanded into, it's zero for other fields
+	//the first minCount of them are required
+	count    int
+	minCount int
+	//order moves the options of the field before the options with a higher order and the same requiredness
+	order int
+
+	//naming derives the name from the naming strategy, it's nil when the name is given by a tag or SetName
+	naming func(s NamingStrategy) string
+
+	//nameKey and descriptionKey override the localization keys derived from the command path
+	nameKey        string
+	descriptionKey string
 
 	autocompleteFn   interface{}
 	autocompleteArgs []*fnArgument
 }
 
+//applicationCommandOption generates the option, the path is the localization key of the command the option belongs to
+func (c *commandArgument) applicationCommandOption(l Localizer, path string) *discordgo.ApplicationCommandOption {
+	path = localizationKey(path, localizationOptions, c.Name)
+	nameKey, descriptionKey := c.nameKey, c.descriptionKey
+	if nameKey == "" {
+		nameKey = localizationKey(path, "name")
+	}
+	if descriptionKey == "" {
+		descriptionKey = localizationKey(path, "description")
+	}
+	o := &discordgo.ApplicationCommandOption{
+		Type:                     c.cType,
+		Name:                     c.Name,
+		Description:              c.Description,
+		NameLocalizations:        localize(l, nameKey),
+		DescriptionLocalizations: localize(l, descriptionKey),
+		Required:                 c.Required,
+		Choices:                  localizeChoices(l, localizationKey(path, "choices"), c.Choices),
+		ChannelTypes:             c.ChannelTypes,
+		Autocomplete:             c.autocompleteFn != nil,
+		MinValue:                 c.MinValue,
+		MinLength:                c.MinLength,
+	}
+	if c.MaxValue != nil {
+		o.MaxValue = *c.MaxValue
+	}
+	if c.MaxLength != nil {
+		o.MaxLength = *c.MaxLength
+	}
+	return o
+}
+
+//applicationCommandOptions generates the option, or the numbered options of a slice field
+//numbered options share the localizations of the field, with the number appended to the name
+func (c *commandArgument) applicationCommandOptions(l Localizer, path string) []*discordgo.ApplicationCommandOption {
+	o := c.applicationCommandOption(l, path)
+	if c.count == 0 {
+		return []*discordgo.ApplicationCommandOption{o}
+	}
+	minCount := c.minCount
+	if c.Required && minCount == 0 {
+		minCount = 1
+	}
+	opts := make([]*discordgo.ApplicationCommandOption, 0, c.count)
+	for n := 1; n <= c.count; n++ {
+		no := *o
+		no.Name = c.Name + strconv.Itoa(n)
+		no.Required = n <= minCount
+		if o.NameLocalizations != nil {
+			no.NameLocalizations = make(map[discordgo.Locale]string, len(o.NameLocalizations))
+			for locale, name := range o.NameLocalizations {
+				no.NameLocalizations[locale] = name + strconv.Itoa(n)
+			}
+		}
+		opts = append(opts, &no)
+	}
+	return opts
+}
+
+//localizeChoices returns copies of the choices with their names localized, keyed by the path and the choice name
+func localizeChoices(l Localizer, path string, choices []*discordgo.ApplicationCommandOptionChoice) []*discordgo.ApplicationCommandOptionChoice {
+	if l == nil || len(choices) == 0 {
+		return choices
+	}
+	o := make([]*discordgo.ApplicationCommandOptionChoice, 0, len(choices))
+	for _, c := range choices {
+		o = append(o, &discordgo.ApplicationCommandOptionChoice{
+			Name:              c.Name,
+			NameLocalizations: localize(l, localizationKey(path, c.Name)),
+			Value:             c.Value,
+		})
+	}
+	return o
+}
+
+//modalArgument is a text input of a modal
+//the embedded commandArgument is used for reconstructing the submitted value, named by the custom id of the text input
+type modalArgument struct {
+	*commandArgument
+
+	Label       string
+	Style       discordgo.TextInputStyle
+	Placeholder string
+	Value       string
+	MinLength   int
+	MaxLength   int
+}
+
 type MetaArgument struct {
 	path []string
 }
@@ -76,6 +208,23 @@ type Unmarshal interface {
 		o []*discordgo.ApplicationCommandInteractionDataOption) error
 }
 
+//OptionUnmarshal is implemented by types of command struct fields that parse the value of their option themselves
+//it's called on a pointer to the field's type, the option is a string unless the type implements OptionType
+type OptionUnmarshal interface {
+	UnmarshalDiskoiOption(opt *discordgo.ApplicationCommandInteractionDataOption, r *Request) error
+}
+
+//OptionType declares the type of the option a type implementing OptionUnmarshal is parsed from
+type OptionType interface {
+	DiskoiOptionType() discordgo.ApplicationCommandOptionType
+}
+
+//Validator is implemented by command structs that validate their fields together once they are reconstructed
+//the returned error is wrapped in CommandValidationError
+type Validator interface {
+	Validate(r Request) error
+}
+
 type ChannelType interface {
 	DiskoiChannelTypes() []discordgo.ChannelType
 }
@@ -84,6 +233,13 @@ type CommandOptions interface {
 	DiskoiCommandOptions() []*discordgo.ApplicationCommandOptionChoice
 }
 
+//Enum is implemented by types with a fixed set of values, every value is offered as a choice named by its String
+//the values must be of the implementing type
+type Enum interface {
+	fmt.Stringer
+	DiskoiEnumValues() []fmt.Stringer
+}
+
 //https://stackoverflow.com/questions/54129042/how-to-get-a-functions-signature-as-string-in-go
 func signature(f interface{}) string {
 	t := reflect.TypeOf(f)
@@ -11,7 +11,9 @@ type CommandGroup struct {
 	description      string
 	subcommandGroups []*SubcommandGroup
 	*SubcommandGroup
-	m sync.RWMutex
+	m      sync.RWMutex
+	locked bool
+	commandPermissions
 
 	chain Chain
 }
@@ -22,7 +24,7 @@ func NewCommandGroup(name string, description string) *CommandGroup {
 	return &CommandGroup{
 		name:            name,
 		description:     description,
-		SubcommandGroup: &SubcommandGroup{},
+		SubcommandGroup: &SubcommandGroup{name: name},
 	}
 }
 
@@ -34,10 +36,32 @@ func (c *CommandGroup) Description() string {
 	return c.description
 }
 
+func (c *CommandGroup) Type() discordgo.ApplicationCommandType {
+	return discordgo.ChatApplicationCommand
+}
+
+//SetChain sets the chain of the command group, it does nothing once the group is locked
+//use TrySetChain to get the error instead
 func (c *CommandGroup) SetChain(chain Chain) {
+	_ = c.TrySetChain(chain)
+}
+
+func (c *CommandGroup) TrySetChain(chain Chain) error {
 	c.m.Lock()
 	defer c.m.Unlock()
+	if c.locked {
+		return c.lockedError()
+	}
 	c.chain = chain
+	return nil
+}
+
+func (c *CommandGroup) MustSetChain(chain Chain) *CommandGroup {
+	err := c.TrySetChain(chain)
+	if err != nil {
+		panic(fmt.Errorf("error setting chain: %w", err))
+	}
+	return c
 }
 
 func (c *CommandGroup) Chain() Chain {
@@ -46,7 +70,7 @@ func (c *CommandGroup) Chain() Chain {
 	return c.chain
 }
 
-func (c *CommandGroup) execute(s *discordgo.Session, i *discordgo.InteractionCreate, pre Chain) error {
+func (c *CommandGroup) execute(s *discordgo.Session, i *discordgo.InteractionCreate, pre Chain, fb ResolveFallback) error {
 	id, ok := i.Data.(discordgo.ApplicationCommandInteractionData)
 	if !ok {
 		return newDiscordExpectationError(
@@ -57,14 +81,14 @@ func (c *CommandGroup) execute(s *discordgo.Session, i *discordgo.InteractionCre
 		return err
 	}
 	chain := pre.Extend(grpChain)
-	err = exec.executeWithOpts(s, i, chain, opts, meta)
+	err = exec.executeWithOpts(s, i, chain, fb, opts, meta)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (c *CommandGroup) autocomplete(s *discordgo.Session, i *discordgo.InteractionCreate) ([]*discordgo.ApplicationCommandOptionChoice, error) {
+func (c *CommandGroup) autocomplete(s *discordgo.Session, i *discordgo.InteractionCreate, fb ResolveFallback) ([]*discordgo.ApplicationCommandOptionChoice, error) {
 	id, ok := i.Data.(discordgo.ApplicationCommandInteractionData)
 	if !ok {
 		return nil, newDiscordExpectationError(
@@ -74,7 +98,7 @@ func (c *CommandGroup) autocomplete(s *discordgo.Session, i *discordgo.Interacti
 	if err != nil {
 		return nil, err
 	}
-	return exec.autocompleteWithOps(s, i, opts, meta)
+	return exec.autocompleteWithOps(s, i, fb, opts, meta)
 }
 
 func (c *CommandGroup) findExecutor(d discordgo.ApplicationCommandInteractionData) (
@@ -120,23 +144,93 @@ func (c *CommandGroup) findExecutor(d discordgo.ApplicationCommandInteractionDat
 	return nil, Chain{}, nil, nil, CommandParsingError{err: fmt.Errorf(`missing subcommand: subcommand "%s" not found on %s`, target.Name, errPath(path))}
 }
 
-func (c *CommandGroup) applicationCommand() *discordgo.ApplicationCommand {
+func (c *CommandGroup) applicationCommand(l Localizer) *discordgo.ApplicationCommand {
 	c.m.RLock()
 	defer c.m.RUnlock()
 	a := &discordgo.ApplicationCommand{
-		Type:        discordgo.ChatApplicationCommand,
-		Name:        c.name,
-		Description: c.description,
-		Options:     []*discordgo.ApplicationCommandOption{},
+		Type:                     discordgo.ChatApplicationCommand,
+		Name:                     c.name,
+		Description:              c.description,
+		NameLocalizations:        localizePtr(l, localizationKey(c.name, "name")),
+		DescriptionLocalizations: localizePtr(l, localizationKey(c.name, "description")),
+		Options:                  []*discordgo.ApplicationCommandOption{},
 	}
-	a.Options = append(a.Options, c.SubcommandGroup.applicationCommandOptions()...)
+	a.Options = append(a.Options, c.SubcommandGroup.applicationCommandOptions(l, c.name)...)
 
 	for _, s := range c.subcommandGroups {
-		a.Options = append(a.Options, s.applicationCommandOption())
+		a.Options = append(a.Options, s.applicationCommandOption(l, c.name))
 	}
+	c.commandPermissions.applyTo(a)
 	return a
 }
 
+//SetDefaultMemberPermissions sets the permissions members need to see the command by default
+func (c *CommandGroup) SetDefaultMemberPermissions(permissions int64) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.locked {
+		return c.lockedError()
+	}
+	c.defaultMemberPermissions = &permissions
+	return nil
+}
+
+func (c *CommandGroup) MustSetDefaultMemberPermissions(permissions int64) *CommandGroup {
+	err := c.SetDefaultMemberPermissions(permissions)
+	if err != nil {
+		panic(fmt.Errorf("error setting default member permissions: %w", err))
+	}
+	return c
+}
+
+//SetDMPermission sets whether the global command is available in DMs
+func (c *CommandGroup) SetDMPermission(allowed bool) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.locked {
+		return c.lockedError()
+	}
+	c.dmPermission = &allowed
+	return nil
+}
+
+func (c *CommandGroup) MustSetDMPermission(allowed bool) *CommandGroup {
+	err := c.SetDMPermission(allowed)
+	if err != nil {
+		panic(fmt.Errorf("error setting dm permission: %w", err))
+	}
+	return c
+}
+
+//SetNSFW sets whether the command is age restricted
+func (c *CommandGroup) SetNSFW(nsfw bool) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.locked {
+		return c.lockedError()
+	}
+	c.nsfw = &nsfw
+	return nil
+}
+
+func (c *CommandGroup) MustSetNSFW(nsfw bool) *CommandGroup {
+	err := c.SetNSFW(nsfw)
+	if err != nil {
+		panic(fmt.Errorf("error setting nsfw: %w", err))
+	}
+	return c
+}
+
+func (c *CommandGroup) Locked() bool {
+	c.m.RLock()
+	defer c.m.RUnlock()
+	return c.locked
+}
+
+func (c *CommandGroup) lockedError() error {
+	return fmt.Errorf(`setting value to command group "%s": cant set value to a locked command group`, c.name)
+}
+
 func (c *CommandGroup) FindSubcommandGroup(name string) (*SubcommandGroup, bool) {
 	c.m.RLock()
 	defer c.m.RUnlock()
@@ -147,25 +241,61 @@ func (c *CommandGroup) FindSubcommandGroup(name string) (*SubcommandGroup, bool)
 	return h, true
 }
 
+//AddSubcommandGroup adds or replaces a subcommand group, it does nothing once the group is locked
+//use TryAddSubcommandGroup to get the error instead
 func (c *CommandGroup) AddSubcommandGroup(group *SubcommandGroup) {
+	_ = c.TryAddSubcommandGroup(group)
+}
+
+func (c *CommandGroup) TryAddSubcommandGroup(group *SubcommandGroup) error {
 	c.m.Lock()
 	defer c.m.Unlock()
+	if c.locked {
+		return c.lockedError()
+	}
 	_, i := c.findGroup(group.name)
 	if i < 0 {
 		c.subcommandGroups = append(c.subcommandGroups, group)
-		return
+		return nil
 	}
 	c.subcommandGroups[i] = group
+	return nil
 }
 
+func (c *CommandGroup) MustAddSubcommandGroup(group *SubcommandGroup) *CommandGroup {
+	err := c.TryAddSubcommandGroup(group)
+	if err != nil {
+		panic(fmt.Errorf("error adding subcommand group: %w", err))
+	}
+	return c
+}
+
+//RemoveSubcommandGroup removes a subcommand group, it does nothing once the group is locked
+//use TryRemoveSubcommandGroup to get the error instead
 func (c *CommandGroup) RemoveSubcommandGroup(name string) {
+	_ = c.TryRemoveSubcommandGroup(name)
+}
+
+func (c *CommandGroup) TryRemoveSubcommandGroup(name string) error {
 	c.m.Lock()
 	defer c.m.Unlock()
+	if c.locked {
+		return c.lockedError()
+	}
 	_, i := c.findGroup(name)
 	if i < 0 {
-		return
+		return nil
 	}
 	c.subcommandGroups = append(c.subcommandGroups[:i], c.subcommandGroups[i+1:]...)
+	return nil
+}
+
+func (c *CommandGroup) MustRemoveSubcommandGroup(name string) *CommandGroup {
+	err := c.TryRemoveSubcommandGroup(name)
+	if err != nil {
+		panic(fmt.Errorf("error removing subcommand group: %w", err))
+	}
+	return c
 }
 
 func (c *CommandGroup) findGroup(name string) (*SubcommandGroup, int) {
@@ -178,8 +308,9 @@ func (c *CommandGroup) findGroup(name string) (*SubcommandGroup, int) {
 }
 
 func (c *CommandGroup) lock() {
-	c.m.RLock()
-	defer c.m.RUnlock()
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.locked = true
 	c.SubcommandGroup.lock()
 	for _, grp := range c.subcommandGroups {
 		grp.lock()
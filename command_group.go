@@ -1,6 +1,7 @@
 package diskoi
 
 import (
+	"fmt"
 	"github.com/bwmarrin/discordgo"
 	"sync"
 )
@@ -10,6 +11,7 @@ type SubcommandGroup struct {
 	description string
 	h           []*Executor
 	m           sync.RWMutex
+	locked      bool
 
 	chain Chain
 }
@@ -21,10 +23,28 @@ func NewSubcommandGroup(name string, description string) *SubcommandGroup {
 	}
 }
 
+//SetChain sets the chain of the subcommand group, it does nothing once the group is locked
+//use TrySetChain to get the error instead
 func (c *SubcommandGroup) SetChain(chain Chain) {
+	_ = c.TrySetChain(chain)
+}
+
+func (c *SubcommandGroup) TrySetChain(chain Chain) error {
 	c.m.Lock()
 	defer c.m.Unlock()
+	if c.locked {
+		return c.lockedError()
+	}
 	c.chain = chain
+	return nil
+}
+
+func (c *SubcommandGroup) MustSetChain(chain Chain) *SubcommandGroup {
+	err := c.TrySetChain(chain)
+	if err != nil {
+		panic(fmt.Errorf("error setting chain: %w", err))
+	}
+	return c
 }
 
 func (c *SubcommandGroup) Chain() Chain {
@@ -33,31 +53,38 @@ func (c *SubcommandGroup) Chain() Chain {
 	return c.chain
 }
 
-func (c *SubcommandGroup) applicationCommandOption() *discordgo.ApplicationCommandOption {
+//applicationCommandOption generates the group as an option, the path is the localization key of the parent command
+func (c *SubcommandGroup) applicationCommandOption(l Localizer, path string) *discordgo.ApplicationCommandOption {
 	c.m.RLock()
 	defer c.m.RUnlock()
+	path = localizationKey(path, localizationOptions, c.name)
 	return &discordgo.ApplicationCommandOption{
-		Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
-		Name:        c.name,
-		Description: c.description,
-		Options:     c.applicationCommandOptionsUnsafe(),
+		Type:                     discordgo.ApplicationCommandOptionSubCommandGroup,
+		Name:                     c.name,
+		Description:              c.description,
+		NameLocalizations:        localize(l, localizationKey(path, "name")),
+		DescriptionLocalizations: localize(l, localizationKey(path, "description")),
+		Options:                  c.applicationCommandOptionsUnsafe(l, path),
 	}
 }
 
-func (c *SubcommandGroup) applicationCommandOptions() []*discordgo.ApplicationCommandOption {
+func (c *SubcommandGroup) applicationCommandOptions(l Localizer, path string) []*discordgo.ApplicationCommandOption {
 	c.m.RLock()
 	defer c.m.RUnlock()
-	return c.applicationCommandOptionsUnsafe()
+	return c.applicationCommandOptionsUnsafe(l, path)
 }
 
-func (c *SubcommandGroup) applicationCommandOptionsUnsafe() []*discordgo.ApplicationCommandOption {
+func (c *SubcommandGroup) applicationCommandOptionsUnsafe(l Localizer, path string) []*discordgo.ApplicationCommandOption {
 	o := make([]*discordgo.ApplicationCommandOption, 0, len(c.h))
 	for _, e := range c.h {
+		subPath := localizationKey(path, localizationOptions, e.name)
 		o = append(o, &discordgo.ApplicationCommandOption{
-			Type:        discordgo.ApplicationCommandOptionSubCommand,
-			Name:        e.name,
-			Description: e.description,
-			Options:     e.applicationCommandOptions(),
+			Type:                     discordgo.ApplicationCommandOptionSubCommand,
+			Name:                     e.name,
+			Description:              e.description,
+			NameLocalizations:        localize(l, localizationKey(subPath, "name")),
+			DescriptionLocalizations: localize(l, localizationKey(subPath, "description")),
+			Options:                  e.applicationCommandOptions(l, subPath),
 		})
 	}
 	return o
@@ -73,25 +100,61 @@ func (c *SubcommandGroup) FindSubcommand(name string) (*Executor, bool) {
 	return h, true
 }
 
+//AddSubcommand adds or replaces a subcommand, it does nothing once the group is locked
+//use TryAddSubcommand to get the error instead
 func (c *SubcommandGroup) AddSubcommand(executor *Executor) {
+	_ = c.TryAddSubcommand(executor)
+}
+
+func (c *SubcommandGroup) TryAddSubcommand(executor *Executor) error {
 	c.m.Lock()
 	defer c.m.Unlock()
+	if c.locked {
+		return c.lockedError()
+	}
 	_, i := c.findSub(executor.name)
 	if i < 0 {
 		c.h = append(c.h, executor)
-		return
+		return nil
 	}
 	c.h[i] = executor
+	return nil
+}
+
+func (c *SubcommandGroup) MustAddSubcommand(executor *Executor) *SubcommandGroup {
+	err := c.TryAddSubcommand(executor)
+	if err != nil {
+		panic(fmt.Errorf("error adding subcommand: %w", err))
+	}
+	return c
 }
 
+//RemoveSubcommand removes a subcommand, it does nothing once the group is locked
+//use TryRemoveSubcommand to get the error instead
 func (c *SubcommandGroup) RemoveSubcommand(name string) {
+	_ = c.TryRemoveSubcommand(name)
+}
+
+func (c *SubcommandGroup) TryRemoveSubcommand(name string) error {
 	c.m.Lock()
 	defer c.m.Unlock()
+	if c.locked {
+		return c.lockedError()
+	}
 	_, i := c.findSub(name)
 	if i < 0 {
-		return
+		return nil
 	}
 	c.h = append(c.h[:i], c.h[i+1:]...)
+	return nil
+}
+
+func (c *SubcommandGroup) MustRemoveSubcommand(name string) *SubcommandGroup {
+	err := c.TryRemoveSubcommand(name)
+	if err != nil {
+		panic(fmt.Errorf("error removing subcommand: %w", err))
+	}
+	return c
 }
 
 func (c *SubcommandGroup) findSub(name string) (*Executor, int) {
@@ -103,9 +166,20 @@ func (c *SubcommandGroup) findSub(name string) (*Executor, int) {
 	return nil, -1
 }
 
+func (c *SubcommandGroup) Locked() bool {
+	c.m.RLock()
+	defer c.m.RUnlock()
+	return c.locked
+}
+
+func (c *SubcommandGroup) lockedError() error {
+	return fmt.Errorf(`setting value to subcommand group "%s": cant set value to a locked subcommand group`, c.name)
+}
+
 func (c *SubcommandGroup) lock() {
 	c.m.Lock()
 	defer c.m.Unlock()
+	c.locked = true
 	for _, exec := range c.h {
 		exec.lock()
 	}
@@ -0,0 +1,174 @@
+package diskoi
+
+import (
+	"context"
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+//ComponentExecutor handles message components(buttons and select menus) with a custom id matching its pattern
+//the pattern may contain parameters in braces, e.g. "vote:{pollID}:{choice}"
+//parameters are mapped into the data struct by option name, so fields must be named with the tag `diskoi:"name:pollID"`
+//or have a lowercase field name matching the parameter
+type ComponentExecutor struct {
+	pattern string
+	matcher *regexp.Regexp
+	params  []string
+	chain   Chain
+	locked  bool
+
+	//fn is the callback function
+	fn interface{}
+	//fnArg is a slice of arguments taken by the function
+	fnArg []*fnArgument
+	//cmdStruct is the struct that parameters will be reconstructed into
+	cmdStruct reflect.Type
+	//cmdArg is a slice of arguments from the struct, each mapped to a parameter
+	cmdArg []*commandArgument
+}
+
+func NewComponentExecutor(pattern string, fn interface{}) (*ComponentExecutor, error) {
+	matcher, params, err := compileComponentPattern(pattern)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to parse component pattern "%s": %w`, pattern, err)
+	}
+	fnArgs, cmdStruct, cmdArg, err := analyzeComponentFn(fn, params)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to parse component "%s": %w`, pattern, err)
+	}
+	return &ComponentExecutor{
+		pattern:   pattern,
+		matcher:   matcher,
+		params:    params,
+		fn:        fn,
+		fnArg:     fnArgs,
+		cmdStruct: cmdStruct,
+		cmdArg:    cmdArg,
+	}, nil
+}
+
+func MustNewComponentExecutor(pattern string, fn interface{}) *ComponentExecutor {
+	executor, err := NewComponentExecutor(pattern, fn)
+	if err != nil {
+		panic(fmt.Errorf("error creating component executor with pattern %s: %w", pattern, err))
+	}
+	return executor
+}
+
+func (c *ComponentExecutor) Pattern() string {
+	return c.pattern
+}
+
+func (c *ComponentExecutor) Chain() Chain {
+	return c.chain
+}
+
+func (c *ComponentExecutor) Locked() bool {
+	return c.locked
+}
+
+func (c *ComponentExecutor) SetChain(chain Chain) error {
+	if c.locked {
+		return fmt.Errorf(`setting value to component "%s": cant set value to a locked component`, c.pattern)
+	}
+	c.chain = chain
+	return nil
+}
+
+func (c *ComponentExecutor) MustSetChain(chain Chain) *ComponentExecutor {
+	err := c.SetChain(chain)
+	if err != nil {
+		panic(fmt.Errorf("error setting chain: %w", err))
+	}
+	return c
+}
+
+func (c *ComponentExecutor) lock() {
+	c.locked = true
+}
+
+//match checks if the custom id matches the pattern, and returns the extracted parameters
+func (c *ComponentExecutor) match(customID string) (map[string]string, bool) {
+	m := c.matcher.FindStringSubmatch(customID)
+	if m == nil {
+		return nil, false
+	}
+	params := make(map[string]string, len(c.params))
+	for i, name := range c.params {
+		params[name] = m[i+1]
+	}
+	return params, true
+}
+
+func (c *ComponentExecutor) execute(s *discordgo.Session, i *discordgo.InteractionCreate, pre Chain, fb ResolveFallback, params map[string]string) error {
+	meta := &MetaArgument{path: []string{c.pattern}}
+	opts, err := reconstructStringOptions(c.cmdArg, params)
+	if err != nil {
+		return CommandParsingError{err: fmt.Errorf(`reconstructing component "%s": %w`, c.pattern, err)}
+	}
+	req := Request{
+		ctx:      context.Background(),
+		ses:      s,
+		ic:       i,
+		opts:     opts,
+		meta:     meta,
+		fallback: fb,
+	}
+	err = pre.Extend(c.Chain()).Then(func(r Request) error {
+		values, err := reconstructFunctionArgs(c.fnArg, c.cmdArg, &r)
+		if err != nil {
+			return CommandParsingError{err: fmt.Errorf(`reconstructing component "%s": %w`, c.pattern, err)}
+		}
+		if err := validateFunctionArgs(c.fnArg, values, &r); err != nil {
+			return err
+		}
+		return callCommandFn(c.fn, values, meta)
+	})(req)
+	return wrapMiddlewareError(err, meta)
+}
+
+//compileComponentPattern compiles a custom id pattern into a regexp and returns the parameter names in order
+func compileComponentPattern(pattern string) (*regexp.Regexp, []string, error) {
+	var params []string
+	buf := strings.Builder{}
+	buf.WriteString("^")
+	rest := pattern
+	for {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			if strings.IndexByte(rest, '}') >= 0 {
+				return nil, nil, fmt.Errorf(`unexpected "}" without matching "{"`)
+			}
+			buf.WriteString(regexp.QuoteMeta(rest))
+			break
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return nil, nil, fmt.Errorf(`unclosed "{" at "%s"`, rest[start:])
+		}
+		end += start
+		literal, name := rest[:start], rest[start+1:end]
+		if strings.IndexByte(literal, '}') >= 0 {
+			return nil, nil, fmt.Errorf(`unexpected "}" without matching "{"`)
+		}
+		if len(name) == 0 || strings.ContainsAny(name, "{") {
+			return nil, nil, fmt.Errorf(`invalid parameter name "%s"`, name)
+		}
+		if containsString(params, name) {
+			return nil, nil, fmt.Errorf(`duplicated parameter "%s"`, name)
+		}
+		params = append(params, name)
+		buf.WriteString(regexp.QuoteMeta(literal))
+		buf.WriteString("(.+?)")
+		rest = rest[end+1:]
+	}
+	buf.WriteString("$")
+	r, err := regexp.Compile(buf.String())
+	if err != nil {
+		return nil, nil, err
+	}
+	return r, params, nil
+}
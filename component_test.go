@@ -0,0 +1,134 @@
+package diskoi
+
+import (
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/require"
+	"regexp"
+	"testing"
+)
+
+func TestCompileComponentPattern(t *testing.T) {
+	cases := []struct {
+		name    string
+		pattern string
+		in      string
+
+		wantParams map[string]string
+		wantErr    *regexp.Regexp
+	}{
+		{
+			name:       "params",
+			pattern:    "vote:{pollID}:{choice}",
+			in:         "vote:123:yes",
+			wantParams: map[string]string{"pollID": "123", "choice": "yes"},
+		}, {
+			name:       "literal",
+			pattern:    "close.ticket",
+			in:         "close.ticket",
+			wantParams: map[string]string{},
+		}, {
+			name:    "literal mismatch",
+			pattern: "close.ticket",
+			in:      "closeXticket",
+		}, {
+			name:    "empty param",
+			pattern: "vote:{pollID}",
+			in:      "vote:",
+		}, {
+			name:    "err unclosed",
+			pattern: "vote:{pollID",
+			wantErr: regexp.MustCompile(`^unclosed "{" at`),
+		}, {
+			name:    "err unopened",
+			pattern: "vote:pollID}",
+			wantErr: regexp.MustCompile(`^unexpected "}" without matching "{"`),
+		}, {
+			name:    "err empty name",
+			pattern: "vote:{}",
+			wantErr: regexp.MustCompile(`^invalid parameter name ""`),
+		}, {
+			name:    "err duplicated",
+			pattern: "{a}:{a}",
+			wantErr: regexp.MustCompile(`^duplicated parameter "a"`),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+			matcher, params, err := compileComponentPattern(tc.pattern)
+			if tc.wantErr != nil {
+				r.Regexp(tc.wantErr, err)
+				return
+			}
+			r.Nil(err)
+			c := &ComponentExecutor{matcher: matcher, params: params}
+			got, ok := c.match(tc.in)
+			if tc.wantParams == nil {
+				r.False(ok)
+				return
+			}
+			r.True(ok)
+			r.Equal(tc.wantParams, got)
+		})
+	}
+}
+
+func TestComponentReconstruct(t *testing.T) {
+	r := require.New(t)
+	type vote struct {
+		PollID uint   `diskoi:"name:pollID"`
+		Choice string `diskoi:"name:choice"`
+		Final  bool   `diskoi:"name:final"`
+	}
+	var got vote
+	var gotData discordgo.MessageComponentInteractionData
+	c, err := NewComponentExecutor("vote:{pollID}:{choice}:{final}",
+		func(cd discordgo.MessageComponentInteractionData, v vote) {
+			got, gotData = v, cd
+		})
+	r.Nil(err)
+
+	cd := discordgo.MessageComponentInteractionData{CustomID: "vote:42:yes:true", Values: []string{"a"}}
+	i := &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{Type: discordgo.InteractionMessageComponent, Data: cd}}
+	params, ok := c.match(cd.CustomID)
+	r.True(ok)
+	r.Nil(c.execute(nil, i, Chain{}, ResolveFallbackState, params))
+	r.Equal(vote{PollID: 42, Choice: "yes", Final: true}, got)
+	r.Equal(cd, gotData)
+
+	params, ok = c.match("vote:foo:yes:true")
+	r.True(ok)
+	err = c.execute(nil, i, Chain{}, ResolveFallbackState, params)
+	r.IsType(CommandParsingError{}, err)
+	r.Regexp(`converting parameter "pollID" into integer`, err)
+}
+
+func TestComponentIntegerParameters(t *testing.T) {
+	r := require.New(t)
+	type ids struct {
+		Message int64  `diskoi:"name:message"`
+		Channel uint64 `diskoi:"name:channel"`
+		Page    *uint8 `diskoi:"name:page,max:10"`
+	}
+	var got ids
+	c, err := NewComponentExecutor("open:{message}:{channel}:{page}", func(v ids) {
+		got = v
+	})
+	r.Nil(err)
+	execute := func(customID string) error {
+		params, ok := c.match(customID)
+		r.True(ok)
+		return c.execute(nil, &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+			Type: discordgo.InteractionMessageComponent,
+			Data: discordgo.MessageComponentInteractionData{CustomID: customID},
+		}}, Chain{}, ResolveFallbackState, params)
+	}
+
+	r.Nil(execute("open:1234567890123456789:18446744073709551615:3"))
+	page := uint8(3)
+	r.Equal(ids{Message: 1234567890123456789, Channel: 18446744073709551615, Page: &page}, got)
+
+	r.Regexp(`converting parameter "channel" into integer: .* invalid syntax$`, execute("open:1:-1:3"))
+	r.Regexp(`value "11" of "Page" is out of range$`, execute("open:1:1:11"))
+	r.Regexp(`converting parameter "message" into integer: .* value out of range$`, execute("open:9223372036854775808:1:3"))
+}
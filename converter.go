@@ -0,0 +1,102 @@
+package diskoi
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+//Converter parses the raw value of a string option into a value of the type it's registered for
+type Converter func(value string) (interface{}, error)
+
+var converters = struct {
+	m   sync.RWMutex
+	reg map[reflect.Type]Converter
+}{reg: map[reflect.Type]Converter{}}
+
+//RegisterConverter registers the converter for the type of sample, fields of that type become string options
+//that are parsed by the converter, registering the same type again replaces the converter
+//commands are analyzed when they are created, so converters should be registered before that
+func RegisterConverter(sample interface{}, c Converter) error {
+	typ := reflect.TypeOf(sample)
+	if typ == nil {
+		return errors.New("cant register converter for nil")
+	}
+	if c == nil {
+		return fmt.Errorf(`cant register nil converter for "%s"`, typ.String())
+	}
+	converters.m.Lock()
+	defer converters.m.Unlock()
+	converters.reg[typ] = c
+	return nil
+}
+
+func MustRegisterConverter(sample interface{}, c Converter) {
+	err := RegisterConverter(sample, c)
+	if err != nil {
+		panic(fmt.Errorf("error registering converter: %w", err))
+	}
+}
+
+//UnregisterConverter removes the converter of the type of sample
+//commands analyzed before keep using the converter
+func UnregisterConverter(sample interface{}) {
+	typ := reflect.TypeOf(sample)
+	if typ == nil {
+		return
+	}
+	converters.m.Lock()
+	defer converters.m.Unlock()
+	delete(converters.reg, typ)
+}
+
+//findConverter finds the converter of typ, or of the type typ points to
+//it returns the type the converter is registered for
+func findConverter(typ reflect.Type) (reflect.Type, Converter) {
+	converters.m.RLock()
+	defer converters.m.RUnlock()
+	if c, ok := converters.reg[typ]; ok {
+		return typ, c
+	}
+	if typ.Kind() == reflect.Ptr {
+		if c, ok := converters.reg[typ.Elem()]; ok {
+			return typ.Elem(), c
+		}
+	}
+	return nil, nil
+}
+
+//convertOption parses the value with the converter of the argument
+//errors of the converter are wrapped in OptionConversionError, so they can be shown to the user
+func convertOption(arg *commandArgument, value string) (reflect.Value, error) {
+	x, err := arg.converter(value)
+	if err != nil {
+		return reflect.Value{}, OptionConversionError{option: arg.Name, value: value, err: err}
+	}
+	v := reflect.ValueOf(x)
+	if !v.IsValid() {
+		return reflect.Zero(arg.convertTyp), nil
+	}
+	if !v.Type().AssignableTo(arg.convertTyp) {
+		return reflect.Value{}, fmt.Errorf(`converter of "%s" returned %s, expecting %s`,
+			arg.fieldName, v.Type().String(), arg.convertTyp.String())
+	}
+	cv := reflect.New(arg.convertTyp).Elem()
+	cv.Set(v)
+	return cv, nil
+}
+
+//setConvertedDefault checks the raw default like a string option, and sets the converted value as the default
+func setConvertedDefault(arg *commandArgument, value string) error {
+	if err := setArgumentDefault(arg, reflect.ValueOf(value)); err != nil {
+		return err
+	}
+	v, err := convertOption(arg, value)
+	if err != nil {
+		arg.defaultValue = reflect.Value{}
+		return fmt.Errorf(`default of field "%s": %w`, arg.fieldName, err)
+	}
+	arg.defaultValue = v
+	return nil
+}
@@ -0,0 +1,122 @@
+package diskoi
+
+import (
+	"errors"
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/require"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testItemID string
+
+type ConvertedArgs struct {
+	Duration time.Duration
+	Link     *url.URL
+	Item     *testItemID `diskoi:"default:item-1,max_length:10"`
+}
+
+func TestConverter(t *testing.T) {
+	r := require.New(t)
+	r.Regexp(`^cant register converter for nil$`, RegisterConverter(nil, func(string) (interface{}, error) { return nil, nil }))
+	r.Regexp(`^cant register nil converter for "int"$`, RegisterConverter(0, nil))
+
+	MustRegisterConverter(time.Duration(0), func(value string) (interface{}, error) {
+		return time.ParseDuration(value)
+	})
+	MustRegisterConverter((*url.URL)(nil), func(value string) (interface{}, error) {
+		return url.Parse(value)
+	})
+	MustRegisterConverter(testItemID(""), func(value string) (interface{}, error) {
+		if !strings.HasPrefix(value, "item-") {
+			return nil, errors.New("items start with item-")
+		}
+		return testItemID(value), nil
+	})
+	t.Cleanup(func() {
+		UnregisterConverter(time.Duration(0))
+		UnregisterConverter((*url.URL)(nil))
+		UnregisterConverter(testItemID(""))
+	})
+
+	cmdArg, err := analyzeCommandStruct(reflect.TypeOf(ConvertedArgs{}), nil)
+	r.Nil(err)
+	for _, arg := range cmdArg {
+		r.Equal(discordgo.ApplicationCommandOptionString, arg.cType, arg.fieldName)
+	}
+
+	reconstruct := func(opts ...*discordgo.ApplicationCommandInteractionDataOption) (interface{}, error) {
+		v, err := reconstructCommandArgument(reflect.TypeOf(ConvertedArgs{}), cmdArg, &Request{fallback: ResolveFallbackNone, opts: opts})
+		if err != nil {
+			return nil, err
+		}
+		return v.Interface(), nil
+	}
+	v, err := reconstruct(
+		&discordgo.ApplicationCommandInteractionDataOption{Name: "duration", Type: discordgo.ApplicationCommandOptionString, Value: "1m30s"},
+		&discordgo.ApplicationCommandInteractionDataOption{Name: "link", Type: discordgo.ApplicationCommandOptionString, Value: "https://example.com/a"},
+		&discordgo.ApplicationCommandInteractionDataOption{Name: "item", Type: discordgo.ApplicationCommandOptionString, Value: "item-2"},
+	)
+	r.Nil(err)
+	link, _ := url.Parse("https://example.com/a")
+	item := testItemID("item-2")
+	r.Equal(ConvertedArgs{Duration: 90 * time.Second, Link: link, Item: &item}, v)
+
+	v, err = reconstruct()
+	r.Nil(err)
+	def := testItemID("item-1")
+	r.Equal(ConvertedArgs{Item: &def}, v)
+
+	_, err = reconstruct(&discordgo.ApplicationCommandInteractionDataOption{Name: "item", Type: discordgo.ApplicationCommandOptionString, Value: "bad"})
+	var ce OptionConversionError
+	r.True(errors.As(err, &ce))
+	r.Regexp(`^invalid value "bad" for option "item": items start with item-$`, err)
+
+	_, err = reconstruct(&discordgo.ApplicationCommandInteractionDataOption{Name: "item", Type: discordgo.ApplicationCommandOptionString, Value: "item-123456"})
+	r.Regexp(`^length "11" of "Item" is out of range$`, err)
+
+	_, err = reconstruct(&discordgo.ApplicationCommandInteractionDataOption{Name: "item", Type: discordgo.ApplicationCommandOptionString, Value: "ite", Focused: true})
+	r.Nil(err)
+
+	_, err = analyzeCommandArgumentField(reflect.StructField{Name: "test", Tag: `diskoi:"default:bad"`, Type: reflect.TypeOf(testItemID(""))})
+	r.Regexp(`^default of field "test": invalid value "bad" for option "test": items start with item-$`, err)
+
+	e := MustNewExecutor("test", "", func(ConvertedArgs) {})
+	err = e.execute(nil, &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		Type: discordgo.InteractionApplicationCommand,
+		Data: discordgo.ApplicationCommandInteractionData{Name: "test", Options: []*discordgo.ApplicationCommandInteractionDataOption{
+			{Name: "item", Type: discordgo.ApplicationCommandOptionString, Value: "bad"},
+		}},
+	}}, Chain{}, ResolveFallbackNone)
+	r.IsType(CommandParsingError{}, err)
+	r.True(errors.As(err, &ce))
+	r.Regexp(`^invalid value "bad" for option "item": items start with item-$`, ce)
+
+	r.Nil(e.SetDefault("Duration", "5s"))
+	r.Equal(5*time.Second, e.cmdArg[0].defaultValue.Interface())
+	r.Regexp(`expecting the string parsed by its converter$`, e.SetDefault("Duration", time.Second))
+	r.Nil(e.SetDefault("Duration", nil))
+	r.False(e.cmdArg[0].defaultValue.IsValid())
+}
+
+func TestUnregisterConverter(t *testing.T) {
+	r := require.New(t)
+	type celsius float64
+	MustRegisterConverter(celsius(0), func(value string) (interface{}, error) {
+		f, err := strconv.ParseFloat(value, 64)
+		return celsius(f), err
+	})
+	typ, c := findConverter(reflect.TypeOf(celsius(0)))
+	r.Equal(reflect.TypeOf(celsius(0)), typ)
+	r.NotNil(c)
+
+	UnregisterConverter(celsius(0))
+	UnregisterConverter(nil)
+	typ, c = findConverter(reflect.TypeOf(celsius(0)))
+	r.Nil(typ)
+	r.Nil(c)
+}
@@ -11,7 +11,16 @@ type Diskoi struct {
 	remover           func()
 	commands          []Command
 	commandsGuild     map[string][]Command
+	suppressed        map[string]map[commandKey]struct{}
+	overridden        map[string]map[commandKey]struct{}
 	registeredCommand map[string]registerMapping
+	components        []*ComponentExecutor
+	modals            map[string]*ModalExecutor
+	syncMode          SyncMode
+	store             RegistryStore
+	localizer         Localizer
+	fallback          ResolveFallback
+	naming            NamingStrategy
 	m                 sync.Mutex
 	errorHandler      errorHandler
 	rawHandler        rawInteractionHandler
@@ -22,7 +31,10 @@ type Diskoi struct {
 func NewDiskoi() *Diskoi {
 	return &Diskoi{
 		commandsGuild:     map[string][]Command{},
+		suppressed:        map[string]map[commandKey]struct{}{},
+		overridden:        map[string]map[commandKey]struct{}{},
 		registeredCommand: map[string]registerMapping{},
+		modals:            map[string]*ModalExecutor{},
 		m:                 sync.Mutex{},
 		errorHandler:      func(s *discordgo.Session, i *discordgo.InteractionCreate, cmd Command, err error) {},
 		rawHandler:        func(session *discordgo.Session, create *discordgo.InteractionCreate) {},
@@ -43,13 +55,13 @@ func (d *Diskoi) handle(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		if !ok {
 			return
 		}
-		e := d.findRegisteredCmdById(id.ID)
+		e := d.findRegisteredCmdById(id.ID, i.GuildID)
 		if e == nil {
 			d.getRawHandler()(s, i)
 			return
 		}
 
-		err := e.execute(s, i, d.chain)
+		err := e.execute(s, i, d.chain, d.ResolveFallback())
 
 		if err != nil {
 			d.getErrorHandler()(s, i, e, err)
@@ -60,12 +72,12 @@ func (d *Diskoi) handle(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		if !ok {
 			return
 		}
-		e := d.findRegisteredCmdById(id.ID)
+		e := d.findRegisteredCmdById(id.ID, i.GuildID)
 		if e == nil {
 			d.getRawHandler()(s, i)
 			return
 		}
-		opts, err := e.autocomplete(s, i)
+		opts, err := e.autocomplete(s, i, d.ResolveFallback())
 
 		if err != nil {
 			d.getErrorHandler()(s, i, e, AutocompleteExecutionError{name: e.Name(), err: err})
@@ -79,6 +91,38 @@ func (d *Diskoi) handle(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		if err != nil {
 			d.getErrorHandler()(s, i, e, DiscordAPIError{err: err})
 		}
+	case i.Type == discordgo.InteractionMessageComponent:
+		cd, ok := i.Data.(discordgo.MessageComponentInteractionData)
+		if !ok {
+			return
+		}
+		c, params := d.findComponent(cd.CustomID)
+		if c == nil {
+			d.getRawHandler()(s, i)
+			return
+		}
+
+		err := c.execute(s, i, d.Chain(), d.ResolveFallback(), params)
+
+		if err != nil {
+			d.getErrorHandler()(s, i, nil, err)
+		}
+	case i.Type == discordgo.InteractionModalSubmit:
+		md, ok := i.Data.(discordgo.ModalSubmitInteractionData)
+		if !ok {
+			return
+		}
+		m := d.findModal(md.CustomID)
+		if m == nil {
+			d.getRawHandler()(s, i)
+			return
+		}
+
+		err := m.execute(s, i, d.Chain(), d.ResolveFallback())
+
+		if err != nil {
+			d.getErrorHandler()(s, i, nil, err)
+		}
 	}
 }
 
@@ -94,13 +138,20 @@ func (d *Diskoi) Chain() Chain {
 	return d.chain
 }
 
-func (d *Diskoi) findRegisteredCmdById(id string) Command {
+//findRegisteredCmdById finds the registered command by id, a global command invoked within a guild
+//resolves to the guild's override, or nil if the guild suppresses it
+func (d *Diskoi) findRegisteredCmdById(id string, guild string) Command {
 	d.m.Lock()
 	defer d.m.Unlock()
-	cmd, _ := d.registeredCommand[id]
-	return cmd.command
+	cmd, ok := d.registeredCommand[id]
+	if !ok || cmd.guild != "" || guild == "" {
+		return cmd.command
+	}
+	return d.resolveGuildCommandUnsafe(guild, cmd.command.Type(), cmd.command.Name())
 }
 
+//SetErrorHandler sets the handler of errors from executing interactions
+//cmd is nil when the error comes from a component or modal, as they aren't commands
 func (d *Diskoi) SetErrorHandler(handler errorHandler) {
 	d.m.Lock()
 	defer d.m.Unlock()
@@ -131,7 +182,11 @@ func (d *Diskoi) Close() error {
 	d.remover()
 	d.commands = nil
 	d.commandsGuild = nil
+	d.suppressed = nil
+	d.overridden = nil
 	d.registeredCommand = nil
+	d.components = nil
+	d.modals = nil
 	d.s = nil
 	return nil
 }
@@ -14,7 +14,27 @@ func (e CommandParsingError) Error() string {
 	return fmt.Sprintf("command parsing error: %v", e.err)
 }
 
-func (e *CommandParsingError) Unwrap() error {
+func (e CommandParsingError) Unwrap() error {
+	return e.err
+}
+
+//CommandValidationError indicates the reconstructed command struct is rejected by its Validator
+//the error of the validator is meant to be shown to the user
+type CommandValidationError struct {
+	name string
+	err  error
+}
+
+func (e CommandValidationError) Error() string {
+	return fmt.Sprintf(`validating "%s": %v`, e.name, e.err)
+}
+
+func (e CommandValidationError) Unwrap() error {
+	return e.err
+}
+
+//Reason returns the error of the validator, which is meant to be shown to the user
+func (e CommandValidationError) Reason() error {
 	return e.err
 }
 
@@ -60,6 +80,22 @@ func (e AutocompleteExecutionError) Unwrap() error {
 	return e.err
 }
 
+//OptionConversionError indicates the value of an option cant be parsed by the converter of its type
+//the message of the converter is kept, so it can be shown to the user
+type OptionConversionError struct {
+	option string
+	value  string
+	err    error
+}
+
+func (e OptionConversionError) Error() string {
+	return fmt.Sprintf(`invalid value "%s" for option "%s": %v`, e.value, e.option, e.err)
+}
+
+func (e OptionConversionError) Unwrap() error {
+	return e.err
+}
+
 //DiscordAPIError is used for warping errors produced by discordgo library
 type DiscordAPIError struct {
 	err error
@@ -73,6 +109,19 @@ func (e DiscordAPIError) Unwrap() error {
 	return e.err
 }
 
+//RegistryStoreError is used for warping errors produced by the RegistryStore
+type RegistryStoreError struct {
+	err error
+}
+
+func (e RegistryStoreError) Error() string {
+	return fmt.Sprintf(`registry store error: %v`, e.err)
+}
+
+func (e RegistryStoreError) Unwrap() error {
+	return e.err
+}
+
 //DiscordExpectationError is used to wrap text that signifies discord api is returning behaving in unexpected way
 type DiscordExpectationError struct {
 	err string
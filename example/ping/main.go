@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"github.com/bwmarrin/discordgo"
@@ -26,7 +27,24 @@ func main() {
 	}
 	d.RegisterSession(s)
 
-	d.SetErrorHandler(func(_ *discordgo.Session, _ *discordgo.InteractionCreate, cmd diskoi.Command, err error) {
+	d.SetErrorHandler(func(s *discordgo.Session, i *discordgo.InteractionCreate, cmd diskoi.Command, err error) {
+		//validation errors are meant for the user, so they are shown only to them
+		var ve diskoi.CommandValidationError
+		if errors.As(err, &ve) {
+			_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: ve.Reason().Error(),
+					Flags:   discordgo.MessageFlagsEphemeral,
+				},
+			})
+			return
+		}
+		//cmd is nil for errors from components and modals
+		if cmd == nil {
+			fmt.Printf("Error on component or modal: %v\n", err)
+			return
+		}
 		fmt.Printf(`Error on command "%s": %v`+"\n", cmd.Name(), err)
 	})
 
@@ -51,7 +69,7 @@ func main() {
 	}
 	defer s.Close()
 
-	stop := make(chan os.Signal)
+	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt)
 	select {
 	case <-stop:
@@ -68,7 +86,8 @@ func main() {
 func addCommands(d *diskoi.Diskoi, guild string) chan *string {
 	shutdown := make(chan *string, 1)
 
-	d.AddGuildCommand(guild, diskoi.MustNewExecutor("ping", "Show the latency between this bot and discord",
+	//commands breaking the limits of discord are rejected when added
+	err := d.AddGuildCommand(guild, diskoi.MustNewExecutor("ping", "Show the latency between this bot and discord",
 		func(s *discordgo.Session, i *discordgo.InteractionCreate) error { //simple ping command that dumps some ping metrics
 			startTime := time.Now()
 			err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
@@ -81,14 +100,18 @@ func addCommands(d *diskoi.Diskoi, guild string) chan *string {
 
 			lastSent := startTime.Sub(s.LastHeartbeatSent)
 			lastAck := startTime.Sub(s.LastHeartbeatAck)
-			_, err = s.InteractionResponseEdit(s.State.User.ID, i.Interaction, &discordgo.WebhookEdit{
-				Content: fmt.Sprintf("Heartbeat latency: %v\nLast sent:%v, Last ack: %v\nInteraction latency: %v\n", s.HeartbeatLatency(), lastSent, lastAck, deferDur),
+			content := fmt.Sprintf("Heartbeat latency: %v\nLast sent:%v, Last ack: %v\nInteraction latency: %v\n", s.HeartbeatLatency(), lastSent, lastAck, deferDur)
+			_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+				Content: &content,
 			})
 			if err != nil {
 				return err
 			}
 			return nil
 		}))
+	if err != nil {
+		panic(err)
+	}
 
 	shutdownCmd := diskoi.MustNewExecutor("shutdown", "Shutdown this bot",
 		func(s *discordgo.Session, i *discordgo.InteractionCreate, arg shutdownArgs) error { //uses custom argument struct
@@ -120,7 +143,10 @@ func addCommands(d *diskoi.Diskoi, guild string) chan *string {
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{Content: "You dont have permission to run this command!"},
 	})))
-	d.AddGuildCommand(guild, shutdownCmd)
+	err = d.AddGuildCommand(guild, shutdownCmd)
+	if err != nil {
+		panic(err)
+	}
 
 	return shutdown
 }
@@ -1,10 +1,12 @@
 package diskoi
 
 import (
+	"context"
 	"fmt"
 	"github.com/bwmarrin/discordgo"
-	"golang.org/x/net/context"
+	"math"
 	"reflect"
+	"sort"
 )
 
 //Executor stores the function, the type and parsed information
@@ -13,6 +15,7 @@ type Executor struct {
 	description string
 	chain       Chain
 	locked      bool
+	commandPermissions
 
 	//fn is the callback function
 	fn interface{}
@@ -23,6 +26,8 @@ type Executor struct {
 	cmdStruct reflect.Type
 	//cmdArg is a slice command arguments from the struct, used for generating ApplicationCommandOptions
 	cmdArg []*commandArgument
+	//naming is the naming strategy of the executor, if it's set the strategy of Diskoi is not applied
+	naming NamingStrategy
 }
 
 var _ Command = (*Executor)(nil)
@@ -36,6 +41,9 @@ func NewExecutor(name string, description string, fn interface{}) (*Executor, er
 	if err != nil {
 		return nil, fmt.Errorf(`failed to parse command "%s": %w`, name, err)
 	}
+	if err := validateOptionNames(cmdArg); err != nil {
+		return nil, fmt.Errorf(`failed to parse command "%s": %w`, name, err)
+	}
 	e.fn, e.fnArg, e.cmdStruct, e.cmdArg = fn, fnArgs, cmdStruct, cmdArg
 	return &e, nil
 }
@@ -48,48 +56,63 @@ func MustNewExecutor(name string, description string, fn interface{}) *Executor
 	return executor
 }
 
-func (e *Executor) execute(s *discordgo.Session, i *discordgo.InteractionCreate, pre Chain) error {
+func (e *Executor) execute(s *discordgo.Session, i *discordgo.InteractionCreate, pre Chain, fb ResolveFallback) error {
 	id, ok := i.Data.(discordgo.ApplicationCommandInteractionData)
 	if !ok {
 		return newDiscordExpectationError(
 			fmt.Sprintf(`given interaction data is not ApplicationCommandInteractionData in command group "/%s"`, e.name))
 	}
-	return e.executeWithOpts(s, i, pre, id.Options, &MetaArgument{path: []string{e.name}})
+	return e.executeWithOpts(s, i, pre, fb, id.Options, &MetaArgument{path: []string{e.name}})
 }
 
-func (e *Executor) executeWithOpts(s *discordgo.Session, i *discordgo.InteractionCreate, pre Chain,
+func (e *Executor) executeWithOpts(s *discordgo.Session, i *discordgo.InteractionCreate, pre Chain, fb ResolveFallback,
 	opts []*discordgo.ApplicationCommandInteractionDataOption, meta *MetaArgument) error {
 	req := Request{
-		ctx:  context.Background(),
-		ses:  s,
-		ic:   i,
-		opts: opts,
-		meta: meta,
-		exec: e,
+		ctx:      context.Background(),
+		ses:      s,
+		ic:       i,
+		opts:     opts,
+		meta:     meta,
+		exec:     e,
+		fallback: fb,
 	}
 	err := pre.Extend(e.Chain()).Then(func(r Request) error {
-		values, err := reconstructFunctionArgs(e.fnArg, e.cmdArg, r.meta, r.ctx, r.ses, r.ic, r.opts)
+		values, err := reconstructFunctionArgs(e.fnArg, e.cmdArg, &r)
 		if err != nil {
 			return CommandParsingError{err: fmt.Errorf(`reconstructing command "%s": %w`, errPath(meta.Path()), err)}
 		}
-		fn := reflect.ValueOf(e.fn)
-		returns := fn.Call(values)
-		if len(returns) > 0 {
-			if err, ok := returns[0].Interface().(error); ok {
-				return CommandExecutionError{
-					name: errPath(meta.path),
-					err:  err,
-				}
-			}
+		if err := validateFunctionArgs(e.fnArg, values, &r); err != nil {
+			return err
 		}
-		return nil
+		return callCommandFn(e.fn, values, meta)
 	})(req)
+	return wrapMiddlewareError(err, meta)
+}
+
+//callCommandFn calls the command function with the reconstructed values
+//and wraps the returned error, if any, in CommandExecutionError
+func callCommandFn(fn interface{}, values []reflect.Value, meta *MetaArgument) error {
+	returns := reflect.ValueOf(fn).Call(values)
+	if len(returns) > 0 {
+		if err, ok := returns[0].Interface().(error); ok {
+			return CommandExecutionError{
+				name: errPath(meta.path),
+				err:  err,
+			}
+		}
+	}
+	return nil
+}
 
+//wrapMiddlewareError wraps errors that did not originate from parsing, validating or executing the command
+//in CommandMiddlewareExecutionError
+func wrapMiddlewareError(err error, meta *MetaArgument) error {
 	if err != nil {
 		_, ok1 := err.(CommandParsingError)
 		_, ok2 := err.(CommandExecutionError)
+		_, ok3 := err.(CommandValidationError)
 
-		if !ok1 && !ok2 {
+		if !ok1 && !ok2 && !ok3 {
 			return CommandMiddlewareExecutionError{
 				name: errPath(meta.Path()),
 				err:  err,
@@ -100,18 +123,27 @@ func (e *Executor) executeWithOpts(s *discordgo.Session, i *discordgo.Interactio
 	return nil
 }
 
-func (e *Executor) autocomplete(s *discordgo.Session, i *discordgo.InteractionCreate) ([]*discordgo.ApplicationCommandOptionChoice, error) {
+func (e *Executor) autocomplete(s *discordgo.Session, i *discordgo.InteractionCreate, fb ResolveFallback) ([]*discordgo.ApplicationCommandOptionChoice, error) {
 	id, ok := i.Data.(discordgo.ApplicationCommandInteractionData)
 	if !ok {
 		return nil, newDiscordExpectationError(
 			fmt.Sprintf(`given interaction data is not ApplicationCommandInteractionData in command group "/%s"`, e.name))
 	}
-	return e.autocompleteWithOps(s, i, id.Options, &MetaArgument{path: []string{e.name}})
+	return e.autocompleteWithOps(s, i, fb, id.Options, &MetaArgument{path: []string{e.name}})
 }
 
-func (e *Executor) autocompleteWithOps(s *discordgo.Session, i *discordgo.InteractionCreate,
+func (e *Executor) autocompleteWithOps(s *discordgo.Session, i *discordgo.InteractionCreate, fb ResolveFallback,
 	opts []*discordgo.ApplicationCommandInteractionDataOption, meta *MetaArgument) ([]*discordgo.ApplicationCommandOptionChoice, error) {
-	arg, values, err := reconstructAutocompleteArgs(e.cmdArg, meta, s, i, opts)
+	req := &Request{
+		ctx:      context.Background(),
+		ses:      s,
+		ic:       i,
+		opts:     opts,
+		meta:     meta,
+		exec:     e,
+		fallback: fb,
+	}
+	arg, values, err := reconstructAutocompleteArgs(e.cmdArg, req)
 	if err != nil {
 		return nil, fmt.Errorf(`error autocompleting command "%s": %w`, errPath(meta.Path()), err)
 	}
@@ -120,31 +152,58 @@ func (e *Executor) autocompleteWithOps(s *discordgo.Session, i *discordgo.Intera
 	return optChoice, nil
 }
 
-func (e *Executor) applicationCommand() *discordgo.ApplicationCommand {
-	return &discordgo.ApplicationCommand{
-		Type:        discordgo.ChatApplicationCommand,
-		Name:        e.name,
-		Description: e.description,
-		Options:     e.applicationCommandOptions(),
-	}
+func (e *Executor) applicationCommand(l Localizer) *discordgo.ApplicationCommand {
+	a := &discordgo.ApplicationCommand{
+		Type:                     discordgo.ChatApplicationCommand,
+		Name:                     e.name,
+		Description:              e.description,
+		NameLocalizations:        localizePtr(l, localizationKey(e.name, "name")),
+		DescriptionLocalizations: localizePtr(l, localizationKey(e.name, "description")),
+		Options:                  e.applicationCommandOptions(l, e.name),
+	}
+	e.commandPermissions.applyTo(a)
+	return a
 }
 
-func (e *Executor) applicationCommandOptions() []*discordgo.ApplicationCommandOption {
+//applicationCommandOptions generates the options, the path is the localization key of the command
+//options are sorted as required options before optional ones, then by their order, otherwise they keep the field order
+func (e *Executor) applicationCommandOptions(l Localizer, path string) []*discordgo.ApplicationCommandOption {
 	o := make([]*discordgo.ApplicationCommandOption, 0, len(e.cmdArg))
+	orders := make([]int, 0, len(e.cmdArg))
 	for _, b := range e.cmdArg {
-		o = append(o, &discordgo.ApplicationCommandOption{
-			Type:         b.cType,
-			Name:         b.Name,
-			Description:  b.Description,
-			Required:     b.Required,
-			Choices:      b.Choices,
-			ChannelTypes: b.ChannelTypes,
-			Autocomplete: b.autocompleteFn != nil,
-		})
+		opts := b.applicationCommandOptions(l, path)
+		o = append(o, opts...)
+		for range opts {
+			orders = append(orders, b.order)
+		}
 	}
+	sort.Stable(optionSorter{opts: o, orders: orders})
 	return o
 }
 
+//optionSorter sorts options along with their orders
+type optionSorter struct {
+	opts   []*discordgo.ApplicationCommandOption
+	orders []int
+}
+
+func (s optionSorter) Len() int {
+	return len(s.opts)
+}
+
+//Less puts required options before optional ones as discord expects, and sorts by order within them
+func (s optionSorter) Less(i, j int) bool {
+	if s.opts[i].Required != s.opts[j].Required {
+		return s.opts[i].Required
+	}
+	return s.orders[i] < s.orders[j]
+}
+
+func (s optionSorter) Swap(i, j int) {
+	s.opts[i], s.opts[j] = s.opts[j], s.opts[i]
+	s.orders[i], s.orders[j] = s.orders[j], s.orders[i]
+}
+
 func (e *Executor) lock() {
 	e.locked = true
 }
@@ -157,6 +216,10 @@ func (e *Executor) Description() string {
 	return e.description
 }
 
+func (e *Executor) Type() discordgo.ApplicationCommandType {
+	return discordgo.ChatApplicationCommand
+}
+
 func (e *Executor) Chain() Chain {
 	return e.chain
 }
@@ -165,17 +228,28 @@ func (e *Executor) Locked() bool {
 	return e.locked
 }
 
+//As copies the executor under another name and description
+//the options are copied as well, so changing or renaming them leaves the original untouched
 func (e *Executor) As(name string, description string) *Executor {
+	cmdArg := make([]*commandArgument, 0, len(e.cmdArg))
+	for _, arg := range e.cmdArg {
+		c := *arg
+		cmdArg = append(cmdArg, &c)
+	}
 	return &Executor{
 		name:        name,
 		description: description,
 		fn:          e.fn,
 		fnArg:       e.fnArg,
 		cmdStruct:   e.cmdStruct,
-		cmdArg:      e.cmdArg,
+		cmdArg:      cmdArg,
 		chain:       e.chain,
+		naming:      e.naming,
+
+		commandPermissions: e.commandPermissions,
 	}
 }
+
 func (e *Executor) MustSetChain(chain Chain) *Executor {
 	err := e.SetChain(chain)
 	if err != nil {
@@ -192,6 +266,60 @@ func (e *Executor) SetChain(chain Chain) error {
 	return nil
 }
 
+//SetDefaultMemberPermissions sets the permissions members need to see the command by default
+//only takes effect when the executor is used as a top level command
+func (e *Executor) SetDefaultMemberPermissions(permissions int64) error {
+	if e.locked {
+		return e.lockedError()
+	}
+	e.defaultMemberPermissions = &permissions
+	return nil
+}
+
+func (e *Executor) MustSetDefaultMemberPermissions(permissions int64) *Executor {
+	err := e.SetDefaultMemberPermissions(permissions)
+	if err != nil {
+		panic(fmt.Errorf("error setting default member permissions: %w", err))
+	}
+	return e
+}
+
+//SetDMPermission sets whether the global command is available in DMs
+//only takes effect when the executor is used as a top level command
+func (e *Executor) SetDMPermission(allowed bool) error {
+	if e.locked {
+		return e.lockedError()
+	}
+	e.dmPermission = &allowed
+	return nil
+}
+
+func (e *Executor) MustSetDMPermission(allowed bool) *Executor {
+	err := e.SetDMPermission(allowed)
+	if err != nil {
+		panic(fmt.Errorf("error setting dm permission: %w", err))
+	}
+	return e
+}
+
+//SetNSFW sets whether the command is age restricted
+//only takes effect when the executor is used as a top level command
+func (e *Executor) SetNSFW(nsfw bool) error {
+	if e.locked {
+		return e.lockedError()
+	}
+	e.nsfw = &nsfw
+	return nil
+}
+
+func (e *Executor) MustSetNSFW(nsfw bool) *Executor {
+	err := e.SetNSFW(nsfw)
+	if err != nil {
+		panic(fmt.Errorf("error setting nsfw: %w", err))
+	}
+	return e
+}
+
 func (e *Executor) SetName(fieldName string, name string) error {
 	if e.locked {
 		return e.lockedError()
@@ -200,7 +328,13 @@ func (e *Executor) SetName(fieldName string, name string) error {
 	if err != nil {
 		return err
 	}
+	old := arg.Name
 	arg.Name = name
+	if err := validateOptionNames(e.cmdArg); err != nil {
+		arg.Name = old
+		return fmt.Errorf(`setting name of field "%s": %w`, fieldName, err)
+	}
+	arg.naming = nil
 	return nil
 }
 
@@ -212,6 +346,95 @@ func (e *Executor) MustSetName(fieldName string, name string) *Executor {
 	return e
 }
 
+//SetNamingStrategy renames the options that aren't named by tags or SetName with the strategy
+//nil returns to the default of lowercasing, and lets the strategy of Diskoi apply
+func (e *Executor) SetNamingStrategy(naming NamingStrategy) error {
+	if e.locked {
+		return e.lockedError()
+	}
+	s := naming
+	if s == nil {
+		s = NamingLower
+	}
+	if err := e.rename(s); err != nil {
+		return err
+	}
+	e.naming = naming
+	return nil
+}
+
+func (e *Executor) MustSetNamingStrategy(naming NamingStrategy) *Executor {
+	err := e.SetNamingStrategy(naming)
+	if err != nil {
+		panic(fmt.Errorf("error setting naming strategy: %w", err))
+	}
+	return e
+}
+
+//useNamingStrategy renames the options with the strategy of Diskoi, unless the executor has its own strategy
+//it returns a function restoring the previous names
+func (e *Executor) useNamingStrategy(naming NamingStrategy) (func(), error) {
+	if e.locked || e.naming != nil {
+		return func() {}, nil
+	}
+	old := e.optionNames()
+	if err := e.rename(naming); err != nil {
+		return nil, err
+	}
+	return func() { e.restoreOptionNames(old) }, nil
+}
+
+//rename renames the options with the strategy, the names are left unchanged if any of the new names are invalid
+func (e *Executor) rename(naming NamingStrategy) error {
+	old := e.optionNames()
+	for _, arg := range e.cmdArg {
+		if arg.naming != nil {
+			arg.Name = arg.naming(naming)
+		}
+	}
+	if err := validateOptionNames(e.cmdArg); err != nil {
+		e.restoreOptionNames(old)
+		return fmt.Errorf(`renaming options of executor "%s": %w`, e.name, err)
+	}
+	return nil
+}
+
+func (e *Executor) optionNames() []string {
+	names := make([]string, len(e.cmdArg))
+	for i, arg := range e.cmdArg {
+		names[i] = arg.Name
+	}
+	return names
+}
+
+func (e *Executor) restoreOptionNames(names []string) {
+	for i, arg := range e.cmdArg {
+		arg.Name = names[i]
+	}
+}
+
+//SetOrder sets the order of the options of the field, lower orders come first
+//required options always come before optional ones, so the order only applies among options of the same requiredness
+func (e *Executor) SetOrder(fieldName string, order int) error {
+	if e.locked {
+		return e.lockedError()
+	}
+	arg, err := e.findField(fieldName)
+	if err != nil {
+		return err
+	}
+	arg.order = order
+	return nil
+}
+
+func (e *Executor) MustSetOrder(fieldName string, order int) *Executor {
+	err := e.SetOrder(fieldName, order)
+	if err != nil {
+		panic(fmt.Errorf("error setting order: %w", err))
+	}
+	return e
+}
+
 func (e *Executor) SetDescription(fieldName string, desc string) error {
 	if e.locked {
 		return e.lockedError()
@@ -240,6 +463,9 @@ func (e *Executor) SetRequired(fieldName string, required bool) error {
 	if err != nil {
 		return err
 	}
+	if required && arg.defaultValue.IsValid() {
+		return fmt.Errorf(`required cant be set on field "%s" with a default`, arg.fieldName)
+	}
 	arg.Required = required
 	return nil
 }
@@ -252,6 +478,107 @@ func (e *Executor) MustSetRequired(fieldName string, required bool) *Executor {
 	return e
 }
 
+//SetDefault sets the value given to the field when the option is absent, nil removes the default
+//the value must be of the field's type, or a number convertible to it without loss
+//fields of types with a converter take the string that is parsed by the converter
+func (e *Executor) SetDefault(fieldName string, value interface{}) error {
+	if e.locked {
+		return e.lockedError()
+	}
+	arg, err := e.findField(fieldName)
+	if err != nil {
+		return err
+	}
+	if arg.converter != nil && value != nil {
+		raw, ok := value.(string)
+		if !ok {
+			return fmt.Errorf(`setting default of field "%s": %T given, expecting the string parsed by its converter`, fieldName, value)
+		}
+		return setConvertedDefault(arg, raw)
+	}
+	typ := e.cmdStruct.FieldByIndex(arg.fieldIndex).Type
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	v, err := convertDefault(typ, value)
+	if err != nil {
+		return fmt.Errorf(`setting default of field "%s": %w`, fieldName, err)
+	}
+	return setArgumentDefault(arg, v)
+}
+
+func (e *Executor) MustSetDefault(fieldName string, value interface{}) *Executor {
+	err := e.SetDefault(fieldName, value)
+	if err != nil {
+		panic(fmt.Errorf("error setting default: %w", err))
+	}
+	return e
+}
+
+//convertDefault converts the value into typ, numbers are converted between kinds as long as nothing is lost
+func convertDefault(typ reflect.Type, value interface{}) (reflect.Value, error) {
+	v := reflect.ValueOf(value)
+	if !v.IsValid() {
+		return v, nil
+	}
+	if !isNumberKind(typ.Kind()) && typ.Kind() != reflect.String && typ.Kind() != reflect.Bool {
+		return reflect.Value{}, fmt.Errorf(`defaults are unsupported on "%s"`, typ.String())
+	}
+	if v.Type().AssignableTo(typ) {
+		dv := reflect.New(typ).Elem()
+		dv.Set(v)
+		return dv, nil
+	}
+	if isNumberKind(v.Kind()) && isNumberKind(typ.Kind()) {
+		if !numberFits(v, typ) || v.Convert(typ).Convert(v.Type()).Interface() != v.Interface() {
+			return reflect.Value{}, fmt.Errorf(`%v cant be converted into %s without loss`, value, typ.String())
+		}
+		return v.Convert(typ), nil
+	}
+	return reflect.Value{}, fmt.Errorf(`%T cant be used as %s`, value, typ.String())
+}
+
+//numberFits checks the sign and range of the number against the integer kind of typ
+//as converting between integer kinds wraps around, which a round trip wont notice, e.g. -1 into uint
+func numberFits(v reflect.Value, typ reflect.Type) bool {
+	dv := reflect.New(typ).Elem()
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return !dv.OverflowInt(v.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return v.Uint() <= math.MaxInt64 && !dv.OverflowInt(int64(v.Uint()))
+		default:
+			f := v.Float()
+			return f >= math.MinInt64 && f < math.MaxInt64 && !dv.OverflowInt(int64(f))
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return v.Int() >= 0 && !dv.OverflowUint(uint64(v.Int()))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return !dv.OverflowUint(v.Uint())
+		default:
+			f := v.Float()
+			return f >= 0 && f < math.MaxUint64 && !dv.OverflowUint(uint64(f))
+		}
+	default:
+		return true
+	}
+}
+
+func isNumberKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
 func (e *Executor) SetChoices(fieldName string, choices []*discordgo.ApplicationCommandOptionChoice) error {
 	if e.locked {
 		return e.lockedError()
@@ -317,6 +644,7 @@ func (e *Executor) MustSetAutoComplete(fieldName string, fn interface{}) *Execut
 	return e
 }
 
+//findField finds the argument by field name, fields of option groups are named by their path, e.g. "Range.Start"
 func (e *Executor) findField(name string) (*commandArgument, error) {
 	for _, arg := range e.cmdArg {
 		if arg.fieldName == name {
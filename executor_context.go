@@ -0,0 +1,196 @@
+package diskoi
+
+import (
+	"context"
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+)
+
+//contextMenuExecutor is the shared implementation of UserCommandExecutor and MessageCommandExecutor
+type contextMenuExecutor struct {
+	name   string
+	cType  discordgo.ApplicationCommandType
+	chain  Chain
+	locked bool
+	commandPermissions
+
+	//fn is the callback function
+	fn interface{}
+	//fnArg is a slice of arguments taken by the function
+	fnArg []*fnArgument
+}
+
+//UserCommandExecutor is a command that shows up in the context menu of users
+//the function may take in the targeted *discordgo.User and *discordgo.Member,
+//the member will be nil if the command is used outside a guild
+type UserCommandExecutor struct {
+	contextMenuExecutor
+}
+
+var _ Command = (*UserCommandExecutor)(nil)
+
+func NewUserCommandExecutor(name string, fn interface{}) (*UserCommandExecutor, error) {
+	c, err := newContextMenuExecutor(name, discordgo.UserApplicationCommand, fn)
+	if err != nil {
+		return nil, err
+	}
+	return &UserCommandExecutor{contextMenuExecutor: c}, nil
+}
+
+func MustNewUserCommandExecutor(name string, fn interface{}) *UserCommandExecutor {
+	executor, err := NewUserCommandExecutor(name, fn)
+	if err != nil {
+		panic(fmt.Errorf("error creating user command executor named %s: %w", name, err))
+	}
+	return executor
+}
+
+func (u *UserCommandExecutor) MustSetChain(chain Chain) *UserCommandExecutor {
+	err := u.SetChain(chain)
+	if err != nil {
+		panic(fmt.Errorf("error setting chain: %w", err))
+	}
+	return u
+}
+
+//MessageCommandExecutor is a command that shows up in the context menu of messages
+//the function may take in the targeted *discordgo.Message
+type MessageCommandExecutor struct {
+	contextMenuExecutor
+}
+
+var _ Command = (*MessageCommandExecutor)(nil)
+
+func NewMessageCommandExecutor(name string, fn interface{}) (*MessageCommandExecutor, error) {
+	c, err := newContextMenuExecutor(name, discordgo.MessageApplicationCommand, fn)
+	if err != nil {
+		return nil, err
+	}
+	return &MessageCommandExecutor{contextMenuExecutor: c}, nil
+}
+
+func MustNewMessageCommandExecutor(name string, fn interface{}) *MessageCommandExecutor {
+	executor, err := NewMessageCommandExecutor(name, fn)
+	if err != nil {
+		panic(fmt.Errorf("error creating message command executor named %s: %w", name, err))
+	}
+	return executor
+}
+
+func (m *MessageCommandExecutor) MustSetChain(chain Chain) *MessageCommandExecutor {
+	err := m.SetChain(chain)
+	if err != nil {
+		panic(fmt.Errorf("error setting chain: %w", err))
+	}
+	return m
+}
+
+func newContextMenuExecutor(name string, cType discordgo.ApplicationCommandType, fn interface{}) (contextMenuExecutor, error) {
+	fnArgs, err := analyzeContextMenuFn(fn, cType)
+	if err != nil {
+		return contextMenuExecutor{}, fmt.Errorf(`failed to parse command "%s": %w`, name, err)
+	}
+	return contextMenuExecutor{
+		name:  name,
+		cType: cType,
+		fn:    fn,
+		fnArg: fnArgs,
+	}, nil
+}
+
+func (c *contextMenuExecutor) execute(s *discordgo.Session, i *discordgo.InteractionCreate, pre Chain, fb ResolveFallback) error {
+	meta := &MetaArgument{path: []string{c.name}}
+	req := Request{
+		ctx:      context.Background(),
+		ses:      s,
+		ic:       i,
+		meta:     meta,
+		fallback: fb,
+	}
+	err := pre.Extend(c.Chain()).Then(func(r Request) error {
+		values, err := reconstructFunctionArgs(c.fnArg, nil, &r)
+		if err != nil {
+			return CommandParsingError{err: fmt.Errorf(`reconstructing command "%s": %w`, errPath(meta.Path()), err)}
+		}
+		return callCommandFn(c.fn, values, meta)
+	})(req)
+	return wrapMiddlewareError(err, meta)
+}
+
+func (c *contextMenuExecutor) autocomplete(*discordgo.Session, *discordgo.InteractionCreate, ResolveFallback) ([]*discordgo.ApplicationCommandOptionChoice, error) {
+	return nil, newDiscordExpectationError(fmt.Sprintf(`autocomplete is not supported on context menu command "%s"`, c.name))
+}
+
+func (c *contextMenuExecutor) applicationCommand(l Localizer) *discordgo.ApplicationCommand {
+	a := &discordgo.ApplicationCommand{
+		Type:              c.cType,
+		Name:              c.name,
+		NameLocalizations: localizePtr(l, localizationKey(c.name, "name")),
+	}
+	c.commandPermissions.applyTo(a)
+	return a
+}
+
+func (c *contextMenuExecutor) lock() {
+	c.locked = true
+}
+
+func (c *contextMenuExecutor) Name() string {
+	return c.name
+}
+
+//Description always returns empty, as context menu commands cant have descriptions
+func (c *contextMenuExecutor) Description() string {
+	return ""
+}
+
+func (c *contextMenuExecutor) Type() discordgo.ApplicationCommandType {
+	return c.cType
+}
+
+func (c *contextMenuExecutor) Chain() Chain {
+	return c.chain
+}
+
+func (c *contextMenuExecutor) Locked() bool {
+	return c.locked
+}
+
+func (c *contextMenuExecutor) SetChain(chain Chain) error {
+	if c.locked {
+		return c.lockedError()
+	}
+	c.chain = chain
+	return nil
+}
+
+//SetDefaultMemberPermissions sets the permissions members need to see the command by default
+func (c *contextMenuExecutor) SetDefaultMemberPermissions(permissions int64) error {
+	if c.locked {
+		return c.lockedError()
+	}
+	c.defaultMemberPermissions = &permissions
+	return nil
+}
+
+//SetDMPermission sets whether the global command is available in DMs
+func (c *contextMenuExecutor) SetDMPermission(allowed bool) error {
+	if c.locked {
+		return c.lockedError()
+	}
+	c.dmPermission = &allowed
+	return nil
+}
+
+//SetNSFW sets whether the command is age restricted
+func (c *contextMenuExecutor) SetNSFW(nsfw bool) error {
+	if c.locked {
+		return c.lockedError()
+	}
+	c.nsfw = &nsfw
+	return nil
+}
+
+func (c *contextMenuExecutor) lockedError() error {
+	return fmt.Errorf(`setting value to executor "%s": cant set value to a locked executor`, c.name)
+}
@@ -3,13 +3,13 @@ module github.com/thunder33345/diskoi
 go 1.17
 
 require (
-	github.com/bwmarrin/discordgo v0.23.3-0.20211204170245-092735083ddf
-	github.com/davecgh/go-spew v1.1.1
+	github.com/BurntSushi/toml v1.2.1
+	github.com/bwmarrin/discordgo v0.27.1
 	github.com/stretchr/testify v1.7.0
-	golang.org/x/net v0.0.0-20211029224645-99673261e6eb
 )
 
 require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/gorilla/websocket v1.4.2 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	golang.org/x/crypto v0.0.0-20210421170649-83a5a9bb288b // indirect
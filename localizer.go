@@ -0,0 +1,175 @@
+package diskoi
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"github.com/bwmarrin/discordgo"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+//Localizer provides the translations of command names, descriptions and choices
+//keys are derived from the command path, e.g. "ban.name", "ban.description", "ban.options.user.description"
+//and "ban.options.reason.choices.spam" for the choice named spam
+//options, subcommands and subcommand groups are kept under "options", so their names cant collide with the keys of their parent
+//options may override their keys with the tags `diskoi:"name_key:...,description_key:..."`
+type Localizer interface {
+	//Localize returns the translations of the key by locale, nil if there are none
+	Localize(key string) map[discordgo.Locale]string
+}
+
+//SetLocalizer sets the localizer used for filling localizations when registering or syncing commands, nil disables it
+func (d *Diskoi) SetLocalizer(l Localizer) {
+	d.m.Lock()
+	defer d.m.Unlock()
+	d.localizer = l
+}
+
+//Catalog is a Localizer backed by message catalogs, holding a set of messages per locale
+type Catalog struct {
+	messages map[string]map[discordgo.Locale]string
+	m        sync.RWMutex
+}
+
+var _ Localizer = (*Catalog)(nil)
+
+func NewCatalog() *Catalog {
+	return &Catalog{messages: map[string]map[discordgo.Locale]string{}}
+}
+
+//LoadCatalog loads every json and toml file in the directory into a new catalog
+//files are named after their locale, e.g. "de.json" or "pt-BR.toml"
+func LoadCatalog(dir string) (*Catalog, error) {
+	c := NewCatalog()
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		ext := filepath.Ext(f.Name())
+		if f.IsDir() || (ext != ".json" && ext != ".toml") {
+			continue
+		}
+		if err := c.LoadFile(filepath.Join(dir, f.Name())); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+//LoadFile loads the messages of a json or toml file, the locale is taken from the file name
+//nested objects and tables are flattened into dot separated keys
+func (c *Catalog) LoadFile(path string) error {
+	ext := filepath.Ext(path)
+	locale := discordgo.Locale(strings.TrimSuffix(filepath.Base(path), ext))
+	if _, ok := discordgo.Locales[locale]; !ok {
+		return fmt.Errorf(`loading catalog "%s": unknown locale "%s"`, path, string(locale))
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf(`loading catalog "%s": %w`, path, err)
+	}
+	raw := map[string]interface{}{}
+	switch ext {
+	case ".json":
+		err = json.Unmarshal(b, &raw)
+	case ".toml":
+		err = toml.Unmarshal(b, &raw)
+	default:
+		return fmt.Errorf(`loading catalog "%s": unsupported file type "%s"`, path, ext)
+	}
+	if err != nil {
+		return fmt.Errorf(`loading catalog "%s": %w`, path, err)
+	}
+	messages := map[string]string{}
+	if err := flattenMessages("", raw, messages); err != nil {
+		return fmt.Errorf(`loading catalog "%s": %w`, path, err)
+	}
+	c.AddMessages(locale, messages)
+	return nil
+}
+
+//AddMessages adds messages of a locale, replacing existing messages with the same key
+func (c *Catalog) AddMessages(locale discordgo.Locale, messages map[string]string) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	for key, msg := range messages {
+		if c.messages[key] == nil {
+			c.messages[key] = map[discordgo.Locale]string{}
+		}
+		c.messages[key][locale] = msg
+	}
+}
+
+func (c *Catalog) Localize(key string) map[discordgo.Locale]string {
+	c.m.RLock()
+	defer c.m.RUnlock()
+	msgs, ok := c.messages[key]
+	if !ok {
+		return nil
+	}
+	cp := make(map[discordgo.Locale]string, len(msgs))
+	for locale, msg := range msgs {
+		cp[locale] = msg
+	}
+	return cp
+}
+
+//flattenMessages flattens nested messages into out, joining the keys with dots
+func flattenMessages(prefix string, in map[string]interface{}, out map[string]string) error {
+	keys := make([]string, 0, len(in))
+	for k := range in {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		switch v := in[k].(type) {
+		case string:
+			out[key] = v
+		case map[string]interface{}:
+			if err := flattenMessages(key, v, out); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf(`message "%s" should be a string or a table, got %T`, key, v)
+		}
+	}
+	return nil
+}
+
+//localizationOptions is the part of a localization key holding the options of a command, subcommand or subcommand group
+const localizationOptions = "options"
+
+//localizationKey joins the parts of a localization key
+func localizationKey(parts ...string) string {
+	return strings.Join(parts, ".")
+}
+
+//localize returns the translations of the key, or nil if there's no localizer or no translations
+func localize(l Localizer, key string) map[discordgo.Locale]string {
+	if l == nil {
+		return nil
+	}
+	msgs := l.Localize(key)
+	if len(msgs) == 0 {
+		return nil
+	}
+	return msgs
+}
+
+//localizePtr is localize for fields of discordgo.ApplicationCommand, which are pointers to maps
+func localizePtr(l Localizer, key string) *map[discordgo.Locale]string {
+	msgs := localize(l, key)
+	if msgs == nil {
+		return nil
+	}
+	return &msgs
+}
@@ -0,0 +1,98 @@
+package diskoi
+
+import (
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestCatalog(t *testing.T) {
+	r := require.New(t)
+	dir := t.TempDir()
+	r.Nil(ioutil.WriteFile(filepath.Join(dir, "de.json"), []byte(`{"ban": {"name": "bannen", "description": "Nutzer bannen"}}`), 0o644))
+	r.Nil(ioutil.WriteFile(filepath.Join(dir, "fr.toml"), []byte("[ban]\nname = \"bannir\"\n"), 0o644))
+	r.Nil(ioutil.WriteFile(filepath.Join(dir, "README.md"), []byte("ignored"), 0o644))
+
+	c, err := LoadCatalog(dir)
+	r.Nil(err)
+	r.Equal(map[discordgo.Locale]string{discordgo.German: "bannen", discordgo.French: "bannir"}, c.Localize("ban.name"))
+	r.Equal(map[discordgo.Locale]string{discordgo.German: "Nutzer bannen"}, c.Localize("ban.description"))
+	r.Nil(c.Localize("kick.name"))
+
+	r.Nil(ioutil.WriteFile(filepath.Join(dir, "xx.json"), []byte(`{}`), 0o644))
+	_, err = LoadCatalog(dir)
+	r.Regexp(`unknown locale "xx"$`, err)
+
+	bad := filepath.Join(t.TempDir(), "de.json")
+	r.Nil(ioutil.WriteFile(bad, []byte(`{"ban": {"name": 1}}`), 0o644))
+	r.Regexp(`message "ban.name" should be a string or a table, got float64$`, NewCatalog().LoadFile(bad))
+}
+
+func TestLocalizeCommand(t *testing.T) {
+	r := require.New(t)
+	type banArgs struct {
+		User   *discordgo.User `diskoi:"name_key:common.user,description_key:common.user.description"`
+		Reason string
+	}
+	ban := MustNewExecutor("ban", "Ban a user", func(banArgs) {}).
+		MustSetChoices("Reason", []*discordgo.ApplicationCommandOptionChoice{{Name: "spam", Value: "spam"}})
+	grp := NewCommandGroup("mod", "Moderation")
+	grp.AddSubcommand(ban)
+
+	c := NewCatalog()
+	c.AddMessages(discordgo.German, map[string]string{
+		"ban.name":                                    "bannen",
+		"common.user":                                 "nutzer",
+		"common.user.description":                     "Der Nutzer",
+		"ban.options.reason.description":              "Der Grund",
+		"ban.options.reason.choices.spam":             "Spam",
+		"mod.options.ban.name":                        "bannen",
+		"mod.options.ban.options.reason.choices.spam": "Werbung",
+	})
+	de := func(s string) map[discordgo.Locale]string {
+		return map[discordgo.Locale]string{discordgo.German: s}
+	}
+
+	ac := ban.applicationCommand(c)
+	r.Equal(de("bannen"), *ac.NameLocalizations)
+	r.Nil(ac.DescriptionLocalizations)
+	r.Equal(de("nutzer"), ac.Options[0].NameLocalizations)
+	r.Equal(de("Der Nutzer"), ac.Options[0].DescriptionLocalizations)
+	r.Nil(ac.Options[1].NameLocalizations)
+	r.Equal(de("Der Grund"), ac.Options[1].DescriptionLocalizations)
+	r.Equal(de("Spam"), ac.Options[1].Choices[0].NameLocalizations)
+
+	gc := grp.applicationCommand(c)
+	r.Nil(gc.NameLocalizations)
+	r.Equal(de("bannen"), gc.Options[0].NameLocalizations)
+	r.Equal(de("Werbung"), gc.Options[0].Options[1].Choices[0].NameLocalizations)
+
+	r.Nil(ban.applicationCommand(nil).Options[1].Choices[0].NameLocalizations)
+}
+
+func TestLocalizeOptionNamedName(t *testing.T) {
+	r := require.New(t)
+	file := filepath.Join(t.TempDir(), "de.json")
+	r.Nil(ioutil.WriteFile(file, []byte(`{"tag": {
+		"name": "etikett",
+		"description": "Etikett setzen",
+		"options": {"name": {"name": "name", "description": "Der Name"}}
+	}}`), 0o644))
+	c := NewCatalog()
+	r.Nil(c.LoadFile(file))
+
+	tag := MustNewExecutor("tag", "Set a tag", func(struct {
+		Name string `diskoi:"description:Name of the tag"`
+	}) {
+	})
+	ac := tag.applicationCommand(c)
+	de := func(s string) map[discordgo.Locale]string {
+		return map[discordgo.Locale]string{discordgo.German: s}
+	}
+	r.Equal(de("etikett"), *ac.NameLocalizations)
+	r.Equal(de("Etikett setzen"), *ac.DescriptionLocalizations)
+	r.Equal(de("name"), ac.Options[0].NameLocalizations)
+	r.Equal(de("Der Name"), ac.Options[0].DescriptionLocalizations)
+}
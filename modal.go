@@ -0,0 +1,155 @@
+package diskoi
+
+import (
+	"context"
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"reflect"
+	"unicode/utf8"
+)
+
+//ModalExecutor stores a modal and the function handling its submission
+//the modal is declared by the last argument of the function, where every field is a text input
+//text inputs are configured with tags, e.g. `diskoi:"label:Reason,style:paragraph,min_length:10,required"`
+type ModalExecutor struct {
+	customID string
+	title    string
+	chain    Chain
+	locked   bool
+
+	//fn is the callback function
+	fn interface{}
+	//fnArg is a slice of arguments taken by the function
+	fnArg []*fnArgument
+	//modalStruct is the struct that submitted values will be reconstructed into
+	modalStruct reflect.Type
+	//modalArgs is a slice of text inputs from the struct, used for generating the modal
+	modalArgs []*modalArgument
+}
+
+func NewModalExecutor(customID string, title string, fn interface{}) (*ModalExecutor, error) {
+	if l := utf8.RuneCountInString(title); l == 0 || l > maxModalLabelLength {
+		return nil, fmt.Errorf(`failed to parse modal "%s": title should be 1 to %d characters long`, customID, maxModalLabelLength)
+	}
+	fnArgs, modalStruct, modalArgs, err := analyzeModalFn(fn)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to parse modal "%s": %w`, customID, err)
+	}
+	return &ModalExecutor{
+		customID:    customID,
+		title:       title,
+		fn:          fn,
+		fnArg:       fnArgs,
+		modalStruct: modalStruct,
+		modalArgs:   modalArgs,
+	}, nil
+}
+
+func MustNewModalExecutor(customID string, title string, fn interface{}) *ModalExecutor {
+	executor, err := NewModalExecutor(customID, title, fn)
+	if err != nil {
+		panic(fmt.Errorf("error creating modal executor %s: %w", customID, err))
+	}
+	return executor
+}
+
+func (m *ModalExecutor) CustomID() string {
+	return m.customID
+}
+
+func (m *ModalExecutor) Title() string {
+	return m.title
+}
+
+func (m *ModalExecutor) Chain() Chain {
+	return m.chain
+}
+
+func (m *ModalExecutor) Locked() bool {
+	return m.locked
+}
+
+func (m *ModalExecutor) SetChain(chain Chain) error {
+	if m.locked {
+		return fmt.Errorf(`setting value to modal "%s": cant set value to a locked modal`, m.customID)
+	}
+	m.chain = chain
+	return nil
+}
+
+func (m *ModalExecutor) MustSetChain(chain Chain) *ModalExecutor {
+	err := m.SetChain(chain)
+	if err != nil {
+		panic(fmt.Errorf("error setting chain: %w", err))
+	}
+	return m
+}
+
+func (m *ModalExecutor) lock() {
+	m.locked = true
+}
+
+//Response returns the interaction response that shows this modal to the user
+func (m *ModalExecutor) Response() *discordgo.InteractionResponse {
+	rows := make([]discordgo.MessageComponent, 0, len(m.modalArgs))
+	for _, arg := range m.modalArgs {
+		rows = append(rows, discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.TextInput{
+					CustomID:    arg.Name,
+					Label:       arg.Label,
+					Style:       arg.Style,
+					Placeholder: arg.Placeholder,
+					Value:       arg.Value,
+					Required:    arg.Required,
+					MinLength:   arg.MinLength,
+					MaxLength:   arg.MaxLength,
+				},
+			},
+		})
+	}
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID:   m.customID,
+			Title:      m.title,
+			Components: rows,
+		},
+	}
+}
+
+func (m *ModalExecutor) execute(s *discordgo.Session, i *discordgo.InteractionCreate, pre Chain, fb ResolveFallback) error {
+	meta := &MetaArgument{path: []string{m.customID}}
+	md, ok := i.Data.(discordgo.ModalSubmitInteractionData)
+	if !ok {
+		return newDiscordExpectationError(
+			fmt.Sprintf(`given interaction data is not ModalSubmitInteractionData in modal "%s"`, m.customID))
+	}
+	cmdArg := make([]*commandArgument, 0, len(m.modalArgs))
+	for _, arg := range m.modalArgs {
+		cmdArg = append(cmdArg, arg.commandArgument)
+	}
+	opts, err := reconstructStringOptions(cmdArg, reconstructModalValues(md.Components))
+	if err != nil {
+		return CommandParsingError{err: fmt.Errorf(`reconstructing modal "%s": %w`, m.customID, err)}
+	}
+	req := Request{
+		ctx:      context.Background(),
+		ses:      s,
+		ic:       i,
+		opts:     opts,
+		meta:     meta,
+		fallback: fb,
+	}
+	err = pre.Extend(m.Chain()).Then(func(r Request) error {
+		values, err := reconstructFunctionArgs(m.fnArg, cmdArg, &r)
+		if err != nil {
+			return CommandParsingError{err: fmt.Errorf(`reconstructing modal "%s": %w`, m.customID, err)}
+		}
+		if err := validateFunctionArgs(m.fnArg, values, &r); err != nil {
+			return err
+		}
+		return callCommandFn(m.fn, values, meta)
+	})(req)
+	return wrapMiddlewareError(err, meta)
+}
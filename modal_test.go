@@ -0,0 +1,177 @@
+package diskoi
+
+import (
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/require"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeModalField(t *testing.T) {
+	cases := []struct {
+		name    string
+		in      reflect.StructField
+		want    *modalArgument
+		wantErr *regexp.Regexp
+	}{
+		{
+			name: "defaults",
+			in:   reflect.StructField{Name: "Reason", Type: reflect.TypeOf("")},
+			want: &modalArgument{
+				commandArgument: &commandArgument{fieldName: "Reason", Name: "reason", cType: discordgo.ApplicationCommandOptionString},
+				Label:           "Reason",
+				Style:           discordgo.TextInputShort,
+			},
+		}, {
+			name: "tags",
+			in: reflect.StructField{Name: "Reason", Type: reflect.TypeOf((*string)(nil)),
+				Tag: `diskoi:"name:why,label:Ban reason,style:paragraph,placeholder:Spam,min_length:10,max_length:500,required"`},
+			want: &modalArgument{
+				commandArgument: &commandArgument{fieldName: "Reason", Name: "why", cType: discordgo.ApplicationCommandOptionString, Required: true},
+				Label:           "Ban reason",
+				Style:           discordgo.TextInputParagraph,
+				Placeholder:     "Spam",
+				MinLength:       10,
+				MaxLength:       500,
+			},
+		}, {
+			name: "number",
+			in:   reflect.StructField{Name: "Days", Type: reflect.TypeOf(uint8(0))},
+			want: &modalArgument{
+				commandArgument: &commandArgument{fieldName: "Days", Name: "days", cType: discordgo.ApplicationCommandOptionInteger},
+				Label:           "Days",
+				Style:           discordgo.TextInputShort,
+			},
+		}, {
+			name:    "err style",
+			in:      reflect.StructField{Name: "Reason", Type: reflect.TypeOf(""), Tag: `diskoi:"style:long"`},
+			wantErr: regexp.MustCompile(`^unrecognized style "long"`),
+		}, {
+			name:    "err length range",
+			in:      reflect.StructField{Name: "Reason", Type: reflect.TypeOf(""), Tag: `diskoi:"max_length:4001"`},
+			wantErr: regexp.MustCompile(`^max_length "4001" out of range`),
+		}, {
+			name:    "err length order",
+			in:      reflect.StructField{Name: "Reason", Type: reflect.TypeOf(""), Tag: `diskoi:"min_length:10,max_length:5"`},
+			wantErr: regexp.MustCompile(`^min_length "10" is larger than max_length "5"`),
+		}, {
+			name:    "err label",
+			in:      reflect.StructField{Name: "Reason", Type: reflect.TypeOf(""), Tag: `diskoi:"label:"`},
+			wantErr: regexp.MustCompile(`^label "" should be 1 to 45 characters long`),
+		}, {
+			name:    "err kind",
+			in:      reflect.StructField{Name: "Reason", Type: reflect.TypeOf(true)},
+			wantErr: regexp.MustCompile(`^unsupported kind "bool"`),
+		}, {
+			name:    "err tag",
+			in:      reflect.StructField{Name: "Reason", Type: reflect.TypeOf(""), Tag: `diskoi:"description:foo"`},
+			wantErr: regexp.MustCompile(`^unrecognized tag "description" with value "foo"`),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := require.New(t)
+			arg, err := analyzeModalField(tc.in)
+			if tc.wantErr != nil {
+				r.Regexp(tc.wantErr, err)
+				return
+			}
+			r.Nil(err)
+			r.Equal(tc.want, arg)
+		})
+	}
+}
+
+func TestAnalyzeModalFn(t *testing.T) {
+	r := require.New(t)
+	_, _, _, err := analyzeModalFn(func(s *discordgo.Session) {})
+	r.Regexp(`is missing the modal struct as the last argument$`, err)
+
+	_, _, _, err = analyzeModalFn(func(m struct{}) {})
+	r.Regexp(`^modal\(struct {}\) has 0 text inputs, expecting 1 to 5$`, err)
+
+	_, _, _, err = analyzeModalFn(func(m struct{ A, B, C, D, E, F string }) {})
+	r.Regexp(`has 6 text inputs, expecting 1 to 5$`, err)
+}
+
+func TestModalExecutor(t *testing.T) {
+	r := require.New(t)
+	type banForm struct {
+		Reason string  `diskoi:"label:Reason,style:paragraph,required"`
+		Days   *int    `diskoi:"label:Delete message days"`
+		Note   *string `diskoi:"label:Note"`
+	}
+	var got banForm
+	m, err := NewModalExecutor("ban", "Ban user", func(md discordgo.ModalSubmitInteractionData, f *banForm) error {
+		got = *f
+		return nil
+	})
+	r.Nil(err)
+
+	resp := m.Response()
+	r.Equal(discordgo.InteractionResponseModal, resp.Type)
+	r.Equal("ban", resp.Data.CustomID)
+	r.Len(resp.Data.Components, 3)
+	r.Equal(discordgo.TextInput{CustomID: "reason", Label: "Reason", Style: discordgo.TextInputParagraph, Required: true},
+		resp.Data.Components[0].(discordgo.ActionsRow).Components[0])
+
+	i := &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		Type: discordgo.InteractionModalSubmit,
+		Data: discordgo.ModalSubmitInteractionData{
+			CustomID: "ban",
+			Components: []discordgo.MessageComponent{
+				&discordgo.ActionsRow{Components: []discordgo.MessageComponent{&discordgo.TextInput{CustomID: "reason", Value: "spam"}}},
+				&discordgo.ActionsRow{Components: []discordgo.MessageComponent{&discordgo.TextInput{CustomID: "days", Value: "7"}}},
+				&discordgo.ActionsRow{Components: []discordgo.MessageComponent{&discordgo.TextInput{CustomID: "note", Value: ""}}},
+			},
+		},
+	}}
+	r.Nil(m.execute(nil, i, Chain{}, ResolveFallbackState))
+	days := 7
+	r.Equal(banForm{Reason: "spam", Days: &days}, got)
+
+	i.Data.(discordgo.ModalSubmitInteractionData).Components[1].(*discordgo.ActionsRow).Components[0].(*discordgo.TextInput).Value = "a week"
+	err = m.execute(nil, i, Chain{}, ResolveFallbackState)
+	r.IsType(CommandParsingError{}, err)
+}
+
+func TestNewModalExecutorTitle(t *testing.T) {
+	_, err := NewModalExecutor("foo", "", func(m struct{ A string }) {})
+	require.Regexp(t, `title should be 1 to 45 characters long$`, err)
+}
+
+func TestModalLimitsCountRunes(t *testing.T) {
+	r := require.New(t)
+	label := strings.Repeat("理", 22)
+	arg, err := analyzeModalField(reflect.StructField{Name: "Reason", Type: reflect.TypeOf(""), Tag: reflect.StructTag(`diskoi:"label:` + label + `"`)})
+	r.Nil(err)
+	r.Equal(label, arg.Label)
+
+	_, err = analyzeModalField(reflect.StructField{Name: "Reason", Type: reflect.TypeOf(""), Tag: reflect.StructTag(`diskoi:"label:` + strings.Repeat("理", 46) + `"`)})
+	r.Regexp(`should be 1 to 45 characters long$`, err)
+
+	_, err = NewModalExecutor("ban", strings.Repeat("理", 45), func(m struct{ A string }) {})
+	r.Nil(err)
+}
+
+func TestAnalyzeModalDuplicateCustomID(t *testing.T) {
+	r := require.New(t)
+	_, _, _, err := analyzeModalFn(func(m struct {
+		A string `diskoi:"name:reason"`
+		B string `diskoi:"name:reason"`
+	}) {
+	})
+	r.Regexp(`custom id "reason" of field "B" is already used by field "A"$`, err)
+
+	type Embedded struct {
+		Reason string
+	}
+	_, _, _, err = analyzeModalFn(func(m struct {
+		Embedded
+		Other string `diskoi:"name:reason"`
+	}) {
+	})
+	r.Regexp(`custom id "reason" of field "Other" is already used by field "Reason"$`, err)
+}
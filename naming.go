@@ -0,0 +1,129 @@
+package diskoi
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+//NamingStrategy converts the go name of a field into the name of its option
+//names given by tags or SetName are kept as they are
+type NamingStrategy func(fieldName string) string
+
+var (
+	//NamingLower lowercases the field name, "TargetUser" into "targetuser", this is the default
+	NamingLower NamingStrategy = strings.ToLower
+	//NamingSnakeCase converts "TargetUser" into "target_user"
+	NamingSnakeCase NamingStrategy = func(fieldName string) string {
+		return strings.Join(splitWords(fieldName), "_")
+	}
+	//NamingKebabCase converts "TargetUser" into "target-user"
+	NamingKebabCase NamingStrategy = func(fieldName string) string {
+		return strings.Join(splitWords(fieldName), "-")
+	}
+)
+
+//splitWords splits a go name into lowercase words, acronyms are kept as a word, e.g. "UserID" into "user" and "id"
+//and digits are kept with the word before them
+func splitWords(name string) []string {
+	rs := []rune(name)
+	var words []string
+	start := 0
+	for i := 1; i < len(rs); i++ {
+		prev, cur := rs[i-1], rs[i]
+		switch {
+		case rs[i] == '_':
+			if i > start {
+				words = append(words, string(rs[start:i]))
+			}
+			start = i + 1
+		case unicode.IsUpper(cur) && (unicode.IsLower(prev) || unicode.IsDigit(prev)):
+			words = append(words, string(rs[start:i]))
+			start = i
+		case unicode.IsUpper(prev) && unicode.IsUpper(cur) && i+1 < len(rs) && unicode.IsLower(rs[i+1]):
+			words = append(words, string(rs[start:i]))
+			start = i
+		}
+	}
+	if start < len(rs) {
+		words = append(words, string(rs[start:]))
+	}
+	for i, w := range words {
+		words[i] = strings.ToLower(w)
+	}
+	return words
+}
+
+//SetNamingStrategy sets the naming strategy applied to executors without their own strategy as they are added
+//it must be set before commands are added, commands whose names would be invalid under it are not added
+func (d *Diskoi) SetNamingStrategy(naming NamingStrategy) {
+	d.m.Lock()
+	defer d.m.Unlock()
+	d.naming = naming
+}
+
+//applyNamingStrategy renames the options of the executors of the command that have no strategy of their own
+//it returns a function restoring the previous names, if any executor cant be renamed, every executor keeps its names
+func applyNamingStrategy(cmd Command, naming NamingStrategy) (func(), error) {
+	var restores []func()
+	restore := func() {
+		for _, r := range restores {
+			r()
+		}
+	}
+	if naming == nil {
+		return restore, nil
+	}
+	var groups []*SubcommandGroup
+	switch c := cmd.(type) {
+	case *Executor:
+		return c.useNamingStrategy(naming)
+	case *CommandGroup:
+		c.m.RLock()
+		groups = append([]*SubcommandGroup{c.SubcommandGroup}, c.subcommandGroups...)
+		c.m.RUnlock()
+	}
+	for _, grp := range groups {
+		grp.m.RLock()
+		for _, e := range grp.h {
+			r, err := e.useNamingStrategy(naming)
+			if err != nil {
+				grp.m.RUnlock()
+				restore()
+				return nil, err
+			}
+			restores = append(restores, r)
+		}
+		grp.m.RUnlock()
+	}
+	return restore, nil
+}
+
+//optionNamePattern is the rule discord applies to the names of commands and options
+var optionNamePattern = regexp.MustCompile(`^[-_\p{L}\p{N}]{1,32}$`)
+
+//validateOptionNames checks every name the arguments generate against the rule of discord, and that they are unique
+func validateOptionNames(args []*commandArgument) error {
+	seen := make(map[string]string, len(args))
+	for _, arg := range args {
+		names := []string{arg.Name}
+		if arg.count > 0 {
+			names = make([]string, 0, arg.count)
+			for n := 1; n <= arg.count; n++ {
+				names = append(names, arg.Name+strconv.Itoa(n))
+			}
+		}
+		for _, name := range names {
+			if !optionNamePattern.MatchString(name) {
+				return fmt.Errorf(`option name "%s" of field "%s" should be 1 to 32 letters, numbers, "-" or "_"`, name, arg.fieldName)
+			}
+			if other, ok := seen[name]; ok {
+				return fmt.Errorf(`option name "%s" of field "%s" is already used by field "%s"`, name, arg.fieldName, other)
+			}
+			seen[name] = arg.fieldName
+		}
+	}
+	return nil
+}
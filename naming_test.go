@@ -0,0 +1,121 @@
+package diskoi
+
+import (
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestNamingStrategy(t *testing.T) {
+	cases := []struct {
+		in    string
+		snake string
+		kebab string
+	}{
+		{"TargetUser", "target_user", "target-user"},
+		{"UserID", "user_id", "user-id"},
+		{"HTTPServer", "http_server", "http-server"},
+		{"Item2Name", "item2_name", "item2-name"},
+		{"Snake_Case", "snake_case", "snake-case"},
+		{"ID", "id", "id"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.in, func(t *testing.T) {
+			r := require.New(t)
+			r.Equal(tc.snake, NamingSnakeCase(tc.in))
+			r.Equal(tc.kebab, NamingKebabCase(tc.in))
+		})
+	}
+}
+
+type testNamingGroup struct {
+	StartAt int `diskoi:"description:Start of the range"`
+}
+
+func TestExecutorNaming(t *testing.T) {
+	r := require.New(t)
+	type args struct {
+		TargetUser *discordgo.User `diskoi:"description:User to target"`
+		Reason     string          `diskoi:"name:why,description:Reason of the action"`
+		Range      testNamingGroup
+		ExtraUsers []*discordgo.User `diskoi:"max:2,description:Other users to target"`
+	}
+	names := func(e *Executor) []string {
+		o := make([]string, 0, len(e.cmdArg))
+		for _, opt := range e.applicationCommandOptions(nil, e.name) {
+			o = append(o, opt.Name)
+		}
+		return o
+	}
+	e := MustNewExecutor("test", "", func(args) {})
+	r.Equal([]string{"targetuser", "why", "range_startat", "extrauser1", "extrauser2"}, names(e))
+
+	r.Nil(e.SetNamingStrategy(NamingSnakeCase))
+	r.Equal([]string{"target_user", "why", "range_start_at", "extra_user1", "extra_user2"}, names(e))
+	r.Nil(e.SetNamingStrategy(NamingKebabCase))
+	r.Equal([]string{"target-user", "why", "range_start-at", "extra-user1", "extra-user2"}, names(e))
+	r.Nil(e.SetName("TargetUser", "target"))
+	r.Nil(e.SetNamingStrategy(strings.ToUpper))
+	r.Equal([]string{"target", "why", "RANGE_STARTAT", "EXTRAUSERS1", "EXTRAUSERS2"}, names(e))
+	r.Nil(e.SetNamingStrategy(nil))
+	r.Equal([]string{"target", "why", "range_startat", "extrauser1", "extrauser2"}, names(e))
+
+	r.Regexp(`option name "range_startat" of field "Range.StartAt" is already used by field "Reason"$`, e.SetName("Reason", "range_startat"))
+	r.Regexp(`option name "a b" of field "Reason" should be 1 to 32 letters, numbers, "-" or "_"$`, e.SetName("Reason", "a b"))
+	r.Regexp(`option name "a{33}_a{33}" of field "Range.StartAt" should be`,
+		e.SetNamingStrategy(func(string) string { return strings.Repeat("a", 33) }))
+	r.Equal([]string{"target", "why", "range_startat", "extrauser1", "extrauser2"}, names(e))
+
+	_, err := NewExecutor("test", "", func(struct {
+		A string `diskoi:"name:b"`
+		B string
+	}) {
+	})
+	r.Regexp(`option name "b" of field "B" is already used by field "A"$`, err)
+
+	d := NewDiskoi()
+	d.SetNamingStrategy(NamingSnakeCase)
+	own := MustNewExecutor("own", "Own strategy", func(args) {}).MustSetNamingStrategy(NamingKebabCase)
+	e = MustNewExecutor("sub", "Strategy of diskoi", func(args) {})
+	g := NewCommandGroup("group", "Group")
+	g.AddSubcommand(e)
+	r.Nil(d.AddCommand(own))
+	r.Nil(d.AddCommand(g))
+	r.Equal([]string{"target-user", "why", "range_start-at", "extra-user1", "extra-user2"}, names(own))
+	r.Equal([]string{"target_user", "why", "range_start_at", "extra_user1", "extra_user2"}, names(e))
+
+	invalid := MustNewExecutor("invalid", "", func(args) {})
+	r.Regexp(`description should be 1 to 100 characters long$`, d.AddCommand(invalid))
+	r.Equal([]string{"targetuser", "why", "range_startat", "extrauser1", "extrauser2"}, names(invalid))
+
+	d.SetNamingStrategy(func(fieldName string) string {
+		if fieldName == "TargetUser" {
+			return strings.Repeat("a", 33)
+		}
+		return NamingSnakeCase(fieldName)
+	})
+	first := MustNewExecutor("first", "First", func(struct{ ExtraNote string }) {})
+	second := MustNewExecutor("second", "Second", func(args) {})
+	g = NewCommandGroup("renamed", "Group")
+	g.AddSubcommand(first)
+	g.AddSubcommand(second)
+	r.Regexp(`^renaming options of executor "second": option name "a{33}" of field "TargetUser" should be`, d.AddCommand(g))
+	r.Equal([]string{"extranote"}, names(first))
+	r.Equal([]string{"targetuser", "why", "range_startat", "extrauser1", "extrauser2"}, names(second))
+	r.Nil(d.FindCommandByName("renamed"))
+
+	d = NewDiskoi()
+	orig := MustNewExecutor("orig", "Original", func(args) {})
+	r.Nil(d.AddCommand(orig))
+	d.SetNamingStrategy(NamingSnakeCase)
+	copied := orig.As("copied", "Copied")
+	r.Nil(d.AddCommand(copied))
+	r.Equal([]string{"targetuser", "why", "range_startat", "extrauser1", "extrauser2"}, names(orig))
+	r.Equal([]string{"target_user", "why", "range_start_at", "extra_user1", "extra_user2"}, names(copied))
+	kebab := own.As("kebab", "Kebab")
+	r.Nil(kebab.SetDescription("Reason", "Why"))
+	r.Equal("Reason of the action", own.cmdArg[1].Description)
+	r.Nil(d.AddCommand(kebab))
+	r.Equal([]string{"target-user", "why", "range_start-at", "extra-user1", "extra-user2"}, names(kebab))
+}
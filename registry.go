@@ -1,119 +1,218 @@
 package diskoi
 
 import (
+	"fmt"
 	"github.com/bwmarrin/discordgo"
-	"reflect"
+	"sort"
 )
 
-func (d *Diskoi) RegisterCommands() error { //todo allow selective registering between guild or global
+//SyncMode decides how commands are pushed to discord by RegisterCommands and SyncCommands
+type SyncMode uint8
+
+const (
+	//SyncModeIncremental creates, updates and deletes commands one request at a time
+	SyncModeIncremental SyncMode = iota
+	//SyncModeBulkOverwrite replaces every command within a scope with a single request
+	//scopes without any changes are skipped when syncing
+	SyncModeBulkOverwrite
+)
+
+func (d *Diskoi) SetSyncMode(mode SyncMode) {
 	d.m.Lock()
 	defer d.m.Unlock()
-	s := d.s
-	f := func(c Command, g string) error {
-		cc, err := s.ApplicationCommandCreate(s.State.User.ID, g, c.applicationCommand())
+	d.syncMode = mode
+}
+
+func (d *Diskoi) SyncMode() SyncMode {
+	d.m.Lock()
+	defer d.m.Unlock()
+	return d.syncMode
+}
+
+//RegisterCommands registers both global and guild commands
+func (d *Diskoi) RegisterCommands() error {
+	d.m.Lock()
+	defer d.m.Unlock()
+	err := d.registerScopeUnsafe("", d.scopeCommandsUnsafe(""))
+	if err != nil {
+		return err
+	}
+	return d.registerGuildsUnsafe(nil)
+}
+
+//RegisterGlobalCommands registers only the global commands
+func (d *Diskoi) RegisterGlobalCommands() error {
+	d.m.Lock()
+	defer d.m.Unlock()
+	return d.registerScopeUnsafe("", d.scopeCommandsUnsafe(""))
+}
+
+//RegisterGuildCommands registers the commands of given guilds, or every guild if none is given
+func (d *Diskoi) RegisterGuildCommands(guildIDs ...string) error {
+	d.m.Lock()
+	defer d.m.Unlock()
+	return d.registerGuildsUnsafe(guildIDs)
+}
+
+func (d *Diskoi) registerGuildsUnsafe(guildIDs []string) error {
+	if len(guildIDs) == 0 {
+		guildIDs = d.scopeGuildsUnsafe()
+	}
+	for _, gid := range guildIDs {
+		err := d.registerScopeUnsafe(gid, d.scopeCommandsUnsafe(gid))
 		if err != nil {
-			return DiscordAPIError{err: err}
-		}
-		d.registeredCommand[cc.ID] = registerMapping{
-			command: c,
-			guild:   g,
+			return err
 		}
-		return nil
 	}
-	for _, cmd := range d.commands {
-		err := f(cmd, "")
+	return nil
+}
+
+func (d *Diskoi) registerScopeUnsafe(guild string, cs []Command) error {
+	if d.syncMode == SyncModeBulkOverwrite {
+		err := d.bulkOverwriteUnsafe(guild, cs)
 		if err != nil {
 			return err
 		}
+		return d.storeScopeUnsafe(guild, cs)
 	}
-	for gid, cms := range d.commandsGuild {
-		for _, cmd := range cms {
-			err := f(cmd, gid)
-			if err != nil {
-				return err
-			}
+	s := d.s
+	for _, c := range cs {
+		cc, err := s.ApplicationCommandCreate(s.State.User.ID, guild, c.applicationCommand(d.localizer))
+		if err != nil {
+			return DiscordAPIError{err: err}
 		}
+		d.mapRegisteredUnsafe(cc.ID, guild, c)
 	}
-	return nil
+	return d.storeScopeUnsafe(guild, cs)
 }
 
-func (d *Diskoi) SyncCommands() error {
-	d.m.Lock()
-	defer d.m.Unlock()
-	f := func(guild string, cs []Command) error {
-		rc, err := d.s.ApplicationCommands(d.s.State.User.ID, guild)
-		if err != nil {
-			return DiscordAPIError{err: err}
+//bulkOverwriteUnsafe overwrites every command within the scope, and maps the returned ids back to the commands
+func (d *Diskoi) bulkOverwriteUnsafe(guild string, cs []Command) error {
+	acs := make([]*discordgo.ApplicationCommand, 0, len(cs))
+	for _, c := range cs {
+		acs = append(acs, c.applicationCommand(d.localizer))
+	}
+	rc, err := d.s.ApplicationCommandBulkOverwrite(d.s.State.User.ID, guild, acs)
+	if err != nil {
+		return DiscordAPIError{err: err}
+	}
+	mapping, err := mapBulkCommands(cs, rc)
+	if err != nil {
+		return err
+	}
+	for id, rCmd := range d.registeredCommand {
+		if rCmd.guild == guild {
+			delete(d.registeredCommand, id)
 		}
-		cMap := make(map[string]*discordgo.ApplicationCommand, len(rc))
-		for _, cmd := range rc {
-			if cmd.Type != discordgo.ChatApplicationCommand {
-				continue
-			}
-			cMap[cmd.Name] = cmd
-		}
-
-		eMap := make(map[string]struct{}, len(cs))
-		for _, c := range cs {
-			eMap[c.Name()] = struct{}{}
-			rc, ok := cMap[c.Name()]
-			eac := c.applicationCommand()
-			if ok {
-				if len(eac.Options) == len(rc.Options) &&
-					eac.Description == rc.Description &&
-					(len(eac.Options) == 0 || reflect.DeepEqual(eac.Options, rc.Options)) {
-					d.registeredCommand[rc.ID] = registerMapping{
-						command: c,
-						guild:   guild,
-					}
-					continue
-				}
-			}
-			cc, err := d.s.ApplicationCommandCreate(d.s.State.User.ID, guild, eac)
-			if err != nil {
-				return DiscordAPIError{err: err}
-			}
-			d.registeredCommand[cc.ID] = registerMapping{
-				command: c,
-				guild:   guild,
-			}
+	}
+	for id, c := range mapping {
+		d.registeredCommand[id] = registerMapping{
+			command: c,
+			guild:   guild,
 		}
+	}
+	return nil
+}
 
-		for cName, cmd := range cMap {
-			_, ok := eMap[cName]
-			if !ok {
-				err = d.s.ApplicationCommandDelete(d.s.State.User.ID, guild, cmd.ID)
-				if err != nil {
-					return DiscordAPIError{err: err}
-				}
-			}
+//mapBulkCommands maps the ids of commands returned by a bulk overwrite to the local commands
+func mapBulkCommands(cs []Command, rc []*discordgo.ApplicationCommand) (map[string]Command, error) {
+	if len(cs) != len(rc) {
+		return nil, newDiscordExpectationError(fmt.Sprintf("bulk overwrite returned %d commands, expecting %d", len(rc), len(cs)))
+	}
+	lookup := make(map[commandKey]Command, len(cs))
+	for _, c := range cs {
+		lookup[commandKey{typ: c.Type(), name: c.Name()}] = c
+	}
+	mapping := make(map[string]Command, len(rc))
+	for _, cmd := range rc {
+		c, ok := lookup[commandKey{typ: cmd.Type, name: cmd.Name}]
+		if !ok {
+			return nil, newDiscordExpectationError(fmt.Sprintf(`bulk overwrite returned unknown %s command "%s"`,
+				commandTypeString(cmd.Type), cmd.Name))
 		}
-		return nil
+		mapping[cmd.ID] = c
+	}
+	return mapping, nil
+}
+
+//SyncCommands plans and applies the changes needed to bring the commands on discord in line with the local commands
+//see PlanSync and ApplySync to inspect the changes before applying them
+func (d *Diskoi) SyncCommands() error {
+	d.m.Lock()
+	defer d.m.Unlock()
+	if err := d.validateCommandsUnsafe(); err != nil {
+		return err
 	}
-	err := f("", d.commands)
+	plan, err := d.planSyncUnsafe()
 	if err != nil {
 		return err
 	}
+	return d.applySyncUnsafe(plan)
+}
+
+//validateCommandsUnsafe validates every command, the errors are collected into InvalidCommandsError
+func (d *Diskoi) validateCommandsUnsafe() error {
+	cs := append([]Command{}, d.commands...)
+	guilds := make([]string, 0, len(d.commandsGuild))
 	for guild := range d.commandsGuild {
-		err := f(guild, d.commandsGuild[guild])
-		if err != nil {
-			return err
-		}
+		guilds = append(guilds, guild)
+	}
+	sort.Strings(guilds)
+	for _, guild := range guilds {
+		cs = append(cs, d.commandsGuild[guild]...)
 	}
+	return validateCommands(cs)
+}
 
+//validateCommands validates given commands, the errors are collected into InvalidCommandsError
+func validateCommands(cs []Command) error {
+	var errs []error
+	for _, c := range cs {
+		if err := c.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return InvalidCommandsError{errs: errs}
+	}
 	return nil
 }
 
+//SyncGuild syncs the commands of a single guild, leaving global commands and other guilds untouched
+//an empty guild syncs only the global commands
+//this is useful for syncing guilds the bot joins, as any leftover commands in the guild are removed
+func (d *Diskoi) SyncGuild(guild string) error {
+	d.m.Lock()
+	defer d.m.Unlock()
+	cs := d.scopeCommandsUnsafe(guild)
+	if err := validateCommands(cs); err != nil {
+		return err
+	}
+	scope, err := d.planScopeUnsafe(guild, cs)
+	if err != nil {
+		return err
+	}
+	return d.applySyncUnsafe(&SyncPlan{Scopes: []*SyncScopePlan{scope}})
+}
+
 func (d *Diskoi) UnregisterAllCommands() error {
 	d.m.Lock()
 	defer d.m.Unlock()
 	s := d.s
-	for id := range d.registeredCommand {
-		err := s.ApplicationCommandDelete(s.State.User.ID, "", id)
+	guilds := map[string]struct{}{}
+	for id, rCmd := range d.registeredCommand {
+		err := s.ApplicationCommandDelete(s.State.User.ID, rCmd.guild, id)
 		if err != nil {
 			return DiscordAPIError{err: err}
 		}
 		delete(d.registeredCommand, id)
+		guilds[rCmd.guild] = struct{}{}
+	}
+	for guild := range guilds {
+		err := d.forgetScopeUnsafe(guild)
+		if err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -126,25 +225,40 @@ func (d *Diskoi) UnregisterCommands(guild string) error {
 		if rCmd.guild != guild {
 			continue
 		}
-		err := s.ApplicationCommandDelete(s.State.User.ID, "", id)
+		err := s.ApplicationCommandDelete(s.State.User.ID, guild, id)
 		if err != nil {
 			return DiscordAPIError{err: err}
 		}
 		delete(d.registeredCommand, id)
 	}
-	return nil
+	return d.forgetScopeUnsafe(guild)
 }
 
-func (d *Diskoi) AddCommand(cmd Command) {
-	d.AddGuildCommand("", cmd)
+//AddCommand adds a global command, commands breaking the limits of discord are not added
+func (d *Diskoi) AddCommand(cmd Command) error {
+	return d.AddGuildCommand("", cmd)
 }
 
-func (d *Diskoi) AddGuildCommand(guild string, cmd Command) {
+//AddGuildCommand adds a command to the guild, commands breaking the limits of discord are not added
+func (d *Diskoi) AddGuildCommand(guild string, cmd Command) error {
 	d.m.Lock()
 	defer d.m.Unlock()
+	return d.addGuildCommandUnsafe(guild, cmd)
+}
+
+func (d *Diskoi) addGuildCommandUnsafe(guild string, cmd Command) error {
+	restore, err := applyNamingStrategy(cmd, d.naming)
+	if err != nil {
+		return err
+	}
+	//rejected commands are left as they were given
+	if err := cmd.Validate(); err != nil {
+		restore()
+		return err
+	}
 	cmd.lock()
 
-	dupe, i := d.findGuildCommandByName(guild, cmd.Name())
+	dupe, i := d.findGuildCommand(guild, cmd.Type(), cmd.Name())
 	if dupe != nil {
 		c, id := d.findRegisteredCmdUnsafe(dupe)
 		if c != nil {
@@ -156,12 +270,14 @@ func (d *Diskoi) AddGuildCommand(guild string, cmd Command) {
 		} else {
 			d.commandsGuild[guild][i] = cmd
 		}
+		return nil
 	}
 	if guild == "" {
 		d.commands = append(d.commands, cmd)
 	} else {
 		d.commandsGuild[guild] = append(d.commandsGuild[guild], cmd)
 	}
+	return nil
 }
 
 func (d *Diskoi) RemoveCommand(cmd Command) error {
@@ -187,37 +303,180 @@ func (d *Diskoi) RemoveGuildCommand(guild string, cmd Command) error {
 		d.commands = f(d.commands, cmd)
 	} else {
 		d.commandsGuild[guild] = f(d.commandsGuild[guild], cmd)
+		if c, _ := d.findGuildCommand(guild, cmd.Type(), cmd.Name()); c == nil {
+			delete(d.overridden[guild], commandKey{typ: cmd.Type(), name: cmd.Name()})
+		}
 	}
 
 	for id, e2 := range d.registeredCommand {
 		if cmd == e2.command {
-			err := d.s.ApplicationCommandDelete(d.s.State.User.ID, guild, id)
+			//global commands suppressed in a guild are registered in each guild, so the guild of the registration is used
+			err := d.s.ApplicationCommandDelete(d.s.State.User.ID, e2.guild, id)
 			if err != nil {
 				return DiscordAPIError{err: err}
 			}
+			delete(d.registeredCommand, id)
 		}
 	}
 	return nil
 }
 
+//FindCommandByName finds a global chat command by name
 func (d *Diskoi) FindCommandByName(name string) Command {
+	return d.FindGuildCommandByType("", discordgo.ChatApplicationCommand, name)
+}
+
+//FindGuildCommandByName finds a chat command in a guild by name, see FindGuildCommandByType
+func (d *Diskoi) FindGuildCommandByName(guild string, name string) Command {
+	return d.FindGuildCommandByType(guild, discordgo.ChatApplicationCommand, name)
+}
+
+//FindGuildCommandByType finds a command in a guild by its type and name
+//as commands of different types are allowed to share the same name
+//it resolves through the guild commands first, then the global commands unless suppressed in the guild
+func (d *Diskoi) FindGuildCommandByType(guild string, typ discordgo.ApplicationCommandType, name string) Command {
 	d.m.Lock()
 	defer d.m.Unlock()
-	c, _ := d.findGuildCommandByName("", name)
-	return c
+	return d.resolveGuildCommandUnsafe(guild, typ, name)
 }
 
-func (d *Diskoi) FindGuildCommandByName(guild string, name string) Command {
+//OverrideGuildCommand adds a guild command that replaces the global command with the same type and name within the guild
+//invocations of the global command within the guild are routed to the override
+//as discord cant hide a global command in a single guild, syncing registers the overridden command
+//in each guild the bot is in except the overriding guilds, instead of globally
+//this costs a request to list the commands of each guild, and one to create each command in them
+//guilds joined later only get the command once they are synced with SyncGuild
+func (d *Diskoi) OverrideGuildCommand(guild string, cmd Command) error {
+	if guild == "" {
+		return fmt.Errorf(`overriding command "%s": cant override in global scope`, cmd.Name())
+	}
+	d.m.Lock()
+	defer d.m.Unlock()
+	global, _ := d.findGuildCommand("", cmd.Type(), cmd.Name())
+	if global == nil {
+		return fmt.Errorf(`overriding command "%s": no global %s command to override`, cmd.Name(), commandTypeString(cmd.Type()))
+	}
+	if err := d.addGuildCommandUnsafe(guild, cmd); err != nil {
+		return err
+	}
+	key := commandKey{typ: cmd.Type(), name: cmd.Name()}
+	delete(d.suppressed[guild], key)
+	if d.overridden[guild] == nil {
+		d.overridden[guild] = map[commandKey]struct{}{}
+	}
+	d.overridden[guild][key] = struct{}{}
+	return nil
+}
+
+//SuppressGuildCommand stops a global command from resolving within a guild
+//as discord cant hide a global command in a single guild, syncing registers the suppressed command
+//in each guild the bot is in except the suppressing guilds, instead of globally
+//this costs a request to list the commands of each guild, and one to create each command in them
+//guilds joined later only get the command once they are synced with SyncGuild
+func (d *Diskoi) SuppressGuildCommand(guild string, typ discordgo.ApplicationCommandType, name string) {
 	d.m.Lock()
 	defer d.m.Unlock()
-	c, _ := d.findGuildCommandByName(guild, name)
+	if d.suppressed[guild] == nil {
+		d.suppressed[guild] = map[commandKey]struct{}{}
+	}
+	d.suppressed[guild][commandKey{typ: typ, name: name}] = struct{}{}
+}
+
+func (d *Diskoi) UnsuppressGuildCommand(guild string, typ discordgo.ApplicationCommandType, name string) {
+	d.m.Lock()
+	defer d.m.Unlock()
+	delete(d.suppressed[guild], commandKey{typ: typ, name: name})
+}
+
+//isLayeredUnsafe checks if the global command is suppressed in any guild, or overridden by OverrideGuildCommand
+//guild commands that merely share the name of the global command dont count
+func (d *Diskoi) isLayeredUnsafe(cmd Command) bool {
+	key := commandKey{typ: cmd.Type(), name: cmd.Name()}
+	for _, layers := range []map[string]map[commandKey]struct{}{d.suppressed, d.overridden} {
+		for _, keys := range layers {
+			if _, ok := keys[key]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+//scopeCommandsUnsafe gets the commands to register within the scope
+//discord cant hide a global command within a single guild, so global commands suppressed or overridden in any guild
+//are left out of the global scope, and are added to the scope of every guild that neither suppresses nor overrides them
+func (d *Diskoi) scopeCommandsUnsafe(guild string) []Command {
+	if guild == "" {
+		cs := make([]Command, 0, len(d.commands))
+		for _, c := range d.commands {
+			if !d.isLayeredUnsafe(c) {
+				cs = append(cs, c)
+			}
+		}
+		return cs
+	}
+	cs := append([]Command{}, d.commandsGuild[guild]...)
+	for _, c := range d.commands {
+		if !d.isLayeredUnsafe(c) {
+			continue
+		}
+		if _, ok := d.suppressed[guild][commandKey{typ: c.Type(), name: c.Name()}]; ok {
+			continue
+		}
+		if o, _ := d.findGuildCommand(guild, c.Type(), c.Name()); o != nil {
+			continue
+		}
+		cs = append(cs, c)
+	}
+	return cs
+}
+
+//scopeGuildsUnsafe gets the guilds to register commands in, sorted by id
+//the guilds the bot is in are included when a global command is suppressed or overridden, as it's registered in each of them
+func (d *Diskoi) scopeGuildsUnsafe() []string {
+	set := make(map[string]struct{}, len(d.commandsGuild))
+	for guild := range d.commandsGuild {
+		set[guild] = struct{}{}
+	}
+	layered := false
+	for _, c := range d.commands {
+		if d.isLayeredUnsafe(c) {
+			layered = true
+			break
+		}
+	}
+	if layered && d.s != nil && d.s.State != nil {
+		d.s.State.RLock()
+		for _, g := range d.s.State.Guilds {
+			set[g.ID] = struct{}{}
+		}
+		d.s.State.RUnlock()
+	}
+	guilds := make([]string, 0, len(set))
+	for guild := range set {
+		guilds = append(guilds, guild)
+	}
+	sort.Strings(guilds)
+	return guilds
+}
+
+func (d *Diskoi) resolveGuildCommandUnsafe(guild string, typ discordgo.ApplicationCommandType, name string) Command {
+	if guild != "" {
+		if c, _ := d.findGuildCommand(guild, typ, name); c != nil {
+			return c
+		}
+		if _, ok := d.suppressed[guild][commandKey{typ: typ, name: name}]; ok {
+			return nil
+		}
+	}
+	c, _ := d.findGuildCommand("", typ, name)
 	return c
 }
 
-func (d *Diskoi) findGuildCommandByName(guild string, name string) (Command, int) {
+func (d *Diskoi) findGuildCommand(guild string, typ discordgo.ApplicationCommandType, name string) (Command, int) {
 	f := func(c []Command) (Command, int) {
 		for i, cmd := range c {
-			if cmd.Name() == name {
+			if cmd.Type() == typ && cmd.Name() == name {
 				return cmd, i
 			}
 		}
@@ -230,6 +489,64 @@ func (d *Diskoi) findGuildCommandByName(guild string, name string) (Command, int
 	return f(d.commandsGuild[guild])
 }
 
+//AddComponent adds a component executor, custom ids are matched against components in the order they are added
+func (d *Diskoi) AddComponent(c *ComponentExecutor) {
+	d.m.Lock()
+	defer d.m.Unlock()
+	c.lock()
+	for i, v := range d.components {
+		if v.pattern == c.pattern {
+			d.components[i] = c
+			return
+		}
+	}
+	d.components = append(d.components, c)
+}
+
+func (d *Diskoi) RemoveComponent(c *ComponentExecutor) {
+	d.m.Lock()
+	defer d.m.Unlock()
+	for i, v := range d.components {
+		if v == c {
+			d.components = append(d.components[:i], d.components[i+1:]...)
+			return
+		}
+	}
+}
+
+func (d *Diskoi) findComponent(customID string) (*ComponentExecutor, map[string]string) {
+	d.m.Lock()
+	defer d.m.Unlock()
+	for _, c := range d.components {
+		if params, ok := c.match(customID); ok {
+			return c, params
+		}
+	}
+	return nil, nil
+}
+
+//AddModal adds a modal executor, replacing any modal with the same custom id
+func (d *Diskoi) AddModal(m *ModalExecutor) {
+	d.m.Lock()
+	defer d.m.Unlock()
+	m.lock()
+	d.modals[m.customID] = m
+}
+
+func (d *Diskoi) RemoveModal(m *ModalExecutor) {
+	d.m.Lock()
+	defer d.m.Unlock()
+	if d.modals[m.customID] == m {
+		delete(d.modals, m.customID)
+	}
+}
+
+func (d *Diskoi) findModal(customID string) *ModalExecutor {
+	d.m.Lock()
+	defer d.m.Unlock()
+	return d.modals[customID]
+}
+
 func (d *Diskoi) findRegisteredCmdUnsafe(cmd Command) (Command, string) {
 	for id, rc := range d.registeredCommand {
 		if cmd == rc.command {
@@ -0,0 +1,264 @@
+package diskoi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/bwmarrin/discordgo"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+//RegistryStore persists the ids of registered commands per scope, along with a hash of the commands sent to discord
+//when the hash of a scope is unchanged, syncing restores the ids from the store instead of contacting discord
+//the store does not notice commands changed on discord by other means, clear it if that happens
+type RegistryStore interface {
+	//LoadScope returns the stored scope of the guild, or nil if nothing is stored
+	LoadScope(guild string) (*StoredScope, error)
+	SaveScope(scope *StoredScope) error
+	DeleteScope(guild string) error
+}
+
+//StoredScope is the stored state of a single scope, the Guild is empty for global commands
+type StoredScope struct {
+	Guild    string          `json:"guild"`
+	Hash     string          `json:"hash"`
+	Commands []StoredCommand `json:"commands"`
+}
+
+//StoredCommand maps a command id to the command it was registered as
+type StoredCommand struct {
+	ID   string                           `json:"id"`
+	Type discordgo.ApplicationCommandType `json:"type"`
+	Name string                           `json:"name"`
+}
+
+//MemoryRegistryStore is a RegistryStore that only lives as long as the process
+type MemoryRegistryStore struct {
+	scopes map[string]*StoredScope
+	m      sync.Mutex
+}
+
+var _ RegistryStore = (*MemoryRegistryStore)(nil)
+
+func NewMemoryRegistryStore() *MemoryRegistryStore {
+	return &MemoryRegistryStore{scopes: map[string]*StoredScope{}}
+}
+
+func (s *MemoryRegistryStore) LoadScope(guild string) (*StoredScope, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.scopes[guild], nil
+}
+
+func (s *MemoryRegistryStore) SaveScope(scope *StoredScope) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.scopes[scope.Guild] = scope
+	return nil
+}
+
+func (s *MemoryRegistryStore) DeleteScope(guild string) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	delete(s.scopes, guild)
+	return nil
+}
+
+//JSONFileRegistryStore is a RegistryStore that keeps every scope in a single json file
+//the file is rewritten on every change, and created if it does not exist
+type JSONFileRegistryStore struct {
+	path string
+	m    sync.Mutex
+}
+
+var _ RegistryStore = (*JSONFileRegistryStore)(nil)
+
+func NewJSONFileRegistryStore(path string) *JSONFileRegistryStore {
+	return &JSONFileRegistryStore{path: path}
+}
+
+func (s *JSONFileRegistryStore) LoadScope(guild string) (*StoredScope, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	scopes, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	return scopes[guild], nil
+}
+
+func (s *JSONFileRegistryStore) SaveScope(scope *StoredScope) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	scopes, err := s.read()
+	if err != nil {
+		return err
+	}
+	scopes[scope.Guild] = scope
+	return s.write(scopes)
+}
+
+func (s *JSONFileRegistryStore) DeleteScope(guild string) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	scopes, err := s.read()
+	if err != nil {
+		return err
+	}
+	delete(scopes, guild)
+	return s.write(scopes)
+}
+
+func (s *JSONFileRegistryStore) read() (map[string]*StoredScope, error) {
+	scopes := map[string]*StoredScope{}
+	b, err := ioutil.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return scopes, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var list []*StoredScope
+	if err := json.Unmarshal(b, &list); err != nil {
+		return nil, err
+	}
+	for _, scope := range list {
+		scopes[scope.Guild] = scope
+	}
+	return scopes, nil
+}
+
+//write writes into a temporary file first, so a crash cant leave a partially written store behind
+func (s *JSONFileRegistryStore) write(scopes map[string]*StoredScope) error {
+	list := make([]*StoredScope, 0, len(scopes))
+	for _, scope := range scopes {
+		list = append(list, scope)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Guild < list[j].Guild
+	})
+	b, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+//SetRegistryStore sets the store used for persisting registered command ids, nil disables it
+func (d *Diskoi) SetRegistryStore(store RegistryStore) {
+	d.m.Lock()
+	defer d.m.Unlock()
+	d.store = store
+}
+
+//scopeHash hashes the commands of a scope, as they would be sent to discord
+func scopeHash(cs []Command, l Localizer) (string, error) {
+	acs := make([]*discordgo.ApplicationCommand, 0, len(cs))
+	for _, c := range cs {
+		acs = append(acs, c.applicationCommand(l))
+	}
+	b, err := json.Marshal(acs)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+//restoreScope plans a scope from the stored scope, where every command is unchanged
+//it returns nil if the stored scope is outdated and discord must be consulted
+func restoreScope(guild string, cs []Command, stored *StoredScope, hash string, l Localizer) *SyncScopePlan {
+	if stored == nil || stored.Hash != hash || len(stored.Commands) != len(cs) {
+		return nil
+	}
+	ids := make(map[commandKey]string, len(stored.Commands))
+	for _, sc := range stored.Commands {
+		ids[commandKey{typ: sc.Type, name: sc.Name}] = sc.ID
+	}
+	scope := &SyncScopePlan{Guild: guild, Stored: true}
+	for _, c := range cs {
+		id, ok := ids[commandKey{typ: c.Type(), name: c.Name()}]
+		if !ok {
+			return nil
+		}
+		scope.Changes = append(scope.Changes, &SyncChange{
+			Action:  SyncActionUnchanged,
+			Name:    c.Name(),
+			Type:    c.Type(),
+			Local:   c.applicationCommand(l),
+			Remote:  &discordgo.ApplicationCommand{ID: id, Type: c.Type(), Name: c.Name()},
+			command: c,
+		})
+	}
+	return scope
+}
+
+//restoreScopeUnsafe tries to plan a scope from the store, returns nil if there's no store or the stored scope is outdated
+func (d *Diskoi) restoreScopeUnsafe(guild string, cs []Command) (*SyncScopePlan, error) {
+	if d.store == nil {
+		return nil, nil
+	}
+	stored, err := d.store.LoadScope(guild)
+	if err != nil {
+		return nil, RegistryStoreError{err: err}
+	}
+	hash, err := scopeHash(cs, d.localizer)
+	if err != nil {
+		return nil, err
+	}
+	return restoreScope(guild, cs, stored, hash, d.localizer), nil
+}
+
+//storeScopeUnsafe saves the registered commands of a scope into the store
+func (d *Diskoi) storeScopeUnsafe(guild string, cs []Command) error {
+	if d.store == nil {
+		return nil
+	}
+	hash, err := scopeHash(cs, d.localizer)
+	if err != nil {
+		return err
+	}
+	scope := &StoredScope{Guild: guild, Hash: hash}
+	for id, rc := range d.registeredCommand {
+		if rc.guild != guild {
+			continue
+		}
+		c := rc.command
+		scope.Commands = append(scope.Commands, StoredCommand{ID: id, Type: c.Type(), Name: c.Name()})
+	}
+	sort.Slice(scope.Commands, func(i, j int) bool {
+		return scope.Commands[i].ID < scope.Commands[j].ID
+	})
+	if err := d.store.SaveScope(scope); err != nil {
+		return RegistryStoreError{err: err}
+	}
+	return nil
+}
+
+//forgetScopeUnsafe deletes the scope from the store
+func (d *Diskoi) forgetScopeUnsafe(guild string) error {
+	if d.store == nil {
+		return nil
+	}
+	if err := d.store.DeleteScope(guild); err != nil {
+		return RegistryStoreError{err: err}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package diskoi
+
+import (
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+)
+
+func TestRegistryStores(t *testing.T) {
+	stores := map[string]RegistryStore{
+		"memory": NewMemoryRegistryStore(),
+		"json":   NewJSONFileRegistryStore(filepath.Join(t.TempDir(), "registry.json")),
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			r := require.New(t)
+			scope, err := store.LoadScope("")
+			r.Nil(err)
+			r.Nil(scope)
+
+			global := &StoredScope{Hash: "abc", Commands: []StoredCommand{{ID: "1", Type: discordgo.ChatApplicationCommand, Name: "ping"}}}
+			guild := &StoredScope{Guild: "10", Hash: "def", Commands: []StoredCommand{{ID: "2", Type: discordgo.UserApplicationCommand, Name: "info"}}}
+			r.Nil(store.SaveScope(global))
+			r.Nil(store.SaveScope(guild))
+
+			scope, err = store.LoadScope("")
+			r.Nil(err)
+			r.Equal(global, scope)
+			scope, err = store.LoadScope("10")
+			r.Nil(err)
+			r.Equal(guild, scope)
+
+			r.Nil(store.DeleteScope("10"))
+			scope, err = store.LoadScope("10")
+			r.Nil(err)
+			r.Nil(scope)
+			scope, err = store.LoadScope("")
+			r.Nil(err)
+			r.Equal(global, scope)
+		})
+	}
+}
+
+func TestRestoreScope(t *testing.T) {
+	r := require.New(t)
+	ping := MustNewExecutor("ping", "Ping the bot", func() {})
+	info := MustNewUserCommandExecutor("info", func() {})
+	cs := []Command{ping, info}
+	hash, err := scopeHash(cs, nil)
+	r.Nil(err)
+
+	changed, err := scopeHash([]Command{MustNewExecutor("ping", "Pong the bot", func() {}), info}, nil)
+	r.Nil(err)
+	r.NotEqual(hash, changed)
+
+	stored := &StoredScope{Guild: "10", Hash: hash, Commands: []StoredCommand{
+		{ID: "2", Type: discordgo.UserApplicationCommand, Name: "info"},
+		{ID: "1", Type: discordgo.ChatApplicationCommand, Name: "ping"},
+	}}
+	scope := restoreScope("10", cs, stored, hash, nil)
+	r.NotNil(scope)
+	r.True(scope.Stored)
+	r.False(scope.HasChanges())
+	r.Len(scope.Changes, 2)
+	r.Equal("1", scope.Changes[0].Remote.ID)
+	r.Equal("2", scope.Changes[1].Remote.ID)
+
+	r.Nil(restoreScope("10", cs, nil, hash, nil))
+	r.Nil(restoreScope("10", cs, stored, changed, nil))
+	stored.Commands[0].Name = "whois"
+	r.Nil(restoreScope("10", cs, stored, hash, nil))
+}
@@ -0,0 +1,256 @@
+package diskoi
+
+import (
+	"encoding/json"
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestMapBulkCommands(t *testing.T) {
+	r := require.New(t)
+	ping := MustNewExecutor("ping", "Ping the bot", func() {})
+	info := MustNewUserCommandExecutor("ping", func() {})
+
+	mapping, err := mapBulkCommands([]Command{ping, info}, []*discordgo.ApplicationCommand{
+		{ID: "2", Type: discordgo.UserApplicationCommand, Name: "ping"},
+		{ID: "1", Type: discordgo.ChatApplicationCommand, Name: "ping"},
+	})
+	r.Nil(err)
+	r.Equal(map[string]Command{"1": ping, "2": info}, mapping)
+
+	_, err = mapBulkCommands([]Command{ping}, nil)
+	r.Regexp(`bulk overwrite returned 0 commands, expecting 1$`, err)
+
+	_, err = mapBulkCommands([]Command{ping}, []*discordgo.ApplicationCommand{
+		{ID: "1", Type: discordgo.MessageApplicationCommand, Name: "ping"},
+	})
+	r.Regexp(`bulk overwrite returned unknown message command "ping"$`, err)
+}
+
+func TestGuildCommandOverrides(t *testing.T) {
+	r := require.New(t)
+	d := NewDiskoi()
+	ban := MustNewExecutor("ban", "Ban a user", func() {})
+	kick := MustNewExecutor("kick", "Kick a user", func() {})
+	banOverride := MustNewExecutor("ban", "Ban a user with extras", func() {})
+	r.Nil(d.AddCommand(ban))
+	r.Nil(d.AddCommand(kick))
+
+	r.Equal(ban, d.FindGuildCommandByName("10", "ban"))
+	r.Nil(d.OverrideGuildCommand("10", banOverride))
+	r.Equal(banOverride, d.FindGuildCommandByName("10", "ban"))
+	r.Equal(ban, d.FindGuildCommandByName("20", "ban"))
+	r.Equal(ban, d.FindCommandByName("ban"))
+
+	r.Regexp(`no global chat command to override$`, d.OverrideGuildCommand("10", MustNewExecutor("mute", "Mute", func() {})))
+	r.Regexp(`cant override in global scope$`, d.OverrideGuildCommand("", banOverride))
+
+	d.SuppressGuildCommand("10", discordgo.ChatApplicationCommand, "kick")
+	r.Nil(d.FindGuildCommandByName("10", "kick"))
+	r.Equal(kick, d.FindGuildCommandByName("20", "kick"))
+	r.Regexp(`description should be 1 to 100 characters long$`, d.OverrideGuildCommand("10", MustNewExecutor("kick", "", func() {})))
+	r.Nil(d.FindGuildCommandByName("10", "kick"))
+
+	d.registeredCommand["1"] = registerMapping{command: ban}
+	d.registeredCommand["2"] = registerMapping{command: kick}
+	r.Equal(banOverride, d.findRegisteredCmdById("1", "10"))
+	r.Equal(ban, d.findRegisteredCmdById("1", "20"))
+	r.Equal(ban, d.findRegisteredCmdById("1", ""))
+	r.Nil(d.findRegisteredCmdById("2", "10"))
+
+	d.UnsuppressGuildCommand("10", discordgo.ChatApplicationCommand, "kick")
+	r.Equal(kick, d.findRegisteredCmdById("2", "10"))
+	r.Nil(d.findRegisteredCmdById("3", "10"))
+}
+
+func TestLayeredCommandScopes(t *testing.T) {
+	r := require.New(t)
+	s, err := discordgo.New("Bot token")
+	r.Nil(err)
+	s.State.User = &discordgo.User{ID: "app"}
+	s.State.Guilds = []*discordgo.Guild{{ID: "10"}, {ID: "20"}, {ID: "30"}}
+	s.Client = &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`[]`)), Request: req}
+	})}
+	d := NewDiskoi()
+	d.s = s
+	ban := MustNewExecutor("ban", "Ban a user", func() {})
+	kick := MustNewExecutor("kick", "Kick a user", func() {})
+	ping := MustNewExecutor("ping", "Ping the bot", func() {})
+	banOverride := MustNewExecutor("ban", "Ban a user with extras", func() {})
+	r.Nil(d.AddCommand(ban))
+	r.Nil(d.AddCommand(kick))
+	r.Nil(d.AddCommand(ping))
+	r.Equal([]string{}, d.scopeGuildsUnsafe())
+	pingGuild := MustNewExecutor("ping", "Ping the guild", func() {})
+	r.Nil(d.AddGuildCommand("40", pingGuild))
+	r.Equal([]Command{ban, kick, ping}, d.scopeCommandsUnsafe(""))
+	r.Equal([]string{"40"}, d.scopeGuildsUnsafe())
+
+	r.Nil(d.OverrideGuildCommand("10", banOverride))
+	d.SuppressGuildCommand("20", discordgo.ChatApplicationCommand, "kick")
+	r.Equal([]Command{ping}, d.scopeCommandsUnsafe(""))
+	r.Equal([]Command{banOverride, kick}, d.scopeCommandsUnsafe("10"))
+	r.Equal([]Command{ban}, d.scopeCommandsUnsafe("20"))
+	r.Equal([]Command{ban, kick}, d.scopeCommandsUnsafe("30"))
+	r.Equal([]Command{pingGuild, ban, kick}, d.scopeCommandsUnsafe("40"))
+	r.Equal([]string{"10", "20", "30", "40"}, d.scopeGuildsUnsafe())
+
+	plan, err := d.PlanSync()
+	r.Nil(err)
+	created := map[string][]string{}
+	for _, scope := range plan.Scopes {
+		for _, c := range scope.Changes {
+			r.Equal(SyncActionCreate, c.Action)
+			created[scope.Guild] = append(created[scope.Guild], c.Local.Description)
+		}
+	}
+	r.Equal(map[string][]string{
+		"":   {"Ping the bot"},
+		"10": {"Ban a user with extras", "Kick a user"},
+		"20": {"Ban a user"},
+		"30": {"Ban a user", "Kick a user"},
+		"40": {"Ping the guild", "Ban a user", "Kick a user"},
+	}, created)
+
+	d.s = nil
+	r.Nil(d.RemoveGuildCommand("10", banOverride))
+	d.UnsuppressGuildCommand("20", discordgo.ChatApplicationCommand, "kick")
+	r.Equal([]Command{ban, kick, ping}, d.scopeCommandsUnsafe(""))
+}
+
+func TestCommandPermissions(t *testing.T) {
+	r := require.New(t)
+	ban := MustNewExecutor("ban", "Ban a user", func() {}).
+		MustSetDefaultMemberPermissions(discordgo.PermissionBanMembers).
+		MustSetDMPermission(false)
+	ac := ban.applicationCommand(nil)
+	r.Equal(int64(discordgo.PermissionBanMembers), *ac.DefaultMemberPermissions)
+	r.False(*ac.DMPermission)
+	r.Nil(ac.NSFW)
+
+	grp := NewCommandGroup("mod", "Moderation")
+	r.Nil(grp.SetNSFW(true))
+	r.True(*grp.applicationCommand(nil).NSFW)
+	users := NewSubcommandGroup("users", "Users")
+	grp.AddSubcommandGroup(users)
+
+	d := NewDiskoi()
+	r.Nil(d.AddCommand(ban))
+	r.Nil(d.AddCommand(grp))
+	r.Regexp(`cant set value to a locked executor$`, ban.SetNSFW(true))
+	r.Regexp(`cant set value to a locked command group$`, grp.SetDMPermission(true))
+	r.Regexp(`cant set value to a locked command group$`, grp.TrySetChain(Chain{}))
+	r.Regexp(`cant set value to a locked command group$`, grp.TryAddSubcommandGroup(NewSubcommandGroup("roles", "Roles")))
+	r.Regexp(`cant set value to a locked command group$`, grp.TryRemoveSubcommandGroup("users"))
+	r.Panics(func() { grp.MustSetNSFW(true) })
+	grp.RemoveSubcommandGroup("users")
+	_, ok := grp.FindSubcommandGroup("users")
+	r.True(ok)
+
+	kick := MustNewExecutor("kick", "Kick a user", func() {})
+	r.Regexp(`cant set value to a locked subcommand group$`, grp.TryAddSubcommand(kick))
+	r.Regexp(`cant set value to a locked subcommand group$`, users.TryAddSubcommand(kick))
+	r.Regexp(`cant set value to a locked subcommand group$`, users.TrySetChain(Chain{}))
+	r.Panics(func() { users.MustRemoveSubcommand("kick") })
+	grp.AddSubcommand(kick)
+	users.AddSubcommand(kick)
+	_, ok = grp.FindSubcommand("kick")
+	r.False(ok)
+	_, ok = users.FindSubcommand("kick")
+	r.False(ok)
+}
+
+//fakeCommandSession returns a session answering command requests like discord, every request is logged into requests
+//listed commands are answered with remote
+func fakeCommandSession(r *require.Assertions, requests *[]string, remote string) *discordgo.Session {
+	s, err := discordgo.New("Bot token")
+	r.Nil(err)
+	s.State.User = &discordgo.User{ID: "app"}
+	id := 100
+	s.Client = &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+		*requests = append(*requests, req.Method+" "+req.URL.Path)
+		respond := func(status int, body string) *http.Response {
+			return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(body)), Request: req}
+		}
+		switch req.Method {
+		case http.MethodGet:
+			return respond(http.StatusOK, remote)
+		case http.MethodDelete:
+			return respond(http.StatusNoContent, "")
+		case http.MethodPost:
+			var c discordgo.ApplicationCommand
+			r.Nil(json.NewDecoder(req.Body).Decode(&c))
+			id++
+			c.ID = strconv.Itoa(id)
+			b, err := json.Marshal(c)
+			r.Nil(err)
+			return respond(http.StatusCreated, string(b))
+		default:
+			var cs []*discordgo.ApplicationCommand
+			r.Nil(json.NewDecoder(req.Body).Decode(&cs))
+			for _, c := range cs {
+				id++
+				c.ID = strconv.Itoa(id)
+			}
+			b, err := json.Marshal(cs)
+			r.Nil(err)
+			return respond(http.StatusOK, string(b))
+		}
+	})}
+	return s
+}
+
+func TestRegisterCommands(t *testing.T) {
+	r := require.New(t)
+	var requests []string
+	ping := MustNewExecutor("ping", "Ping the bot", func() {})
+	echo := MustNewExecutor("echo", "Echo text", func() {})
+	d := NewDiskoi()
+	d.s = fakeCommandSession(r, &requests, "[]")
+	r.Nil(d.AddCommand(ping))
+	r.Nil(d.AddGuildCommand("10", echo))
+
+	r.Nil(d.RegisterGlobalCommands())
+	r.Equal([]string{"POST /api/v9/applications/app/commands"}, requests)
+	r.Equal(map[string]registerMapping{"101": {command: ping}}, d.registeredCommand)
+
+	requests = nil
+	r.Nil(d.RegisterGuildCommands("10"))
+	r.Equal([]string{"POST /api/v9/applications/app/guilds/10/commands"}, requests)
+	r.Equal(map[string]registerMapping{"101": {command: ping}, "102": {command: echo, guild: "10"}}, d.registeredCommand)
+
+	requests = nil
+	d.SetSyncMode(SyncModeBulkOverwrite)
+	r.Nil(d.RegisterGuildCommands())
+	r.Equal([]string{"PUT /api/v9/applications/app/guilds/10/commands"}, requests)
+	r.Equal(map[string]registerMapping{"101": {command: ping}, "103": {command: echo, guild: "10"}}, d.registeredCommand)
+}
+
+func TestSyncGuild(t *testing.T) {
+	r := require.New(t)
+	var requests []string
+	echo := MustNewExecutor("echo", "Echo text", func() {})
+	d := NewDiskoi()
+	d.s = fakeCommandSession(r, &requests, `[{"id":"7","type":1,"name":"old","description":"Removed command"}]`)
+	r.Nil(d.AddGuildCommand("10", echo))
+
+	r.Nil(d.SyncGuild("10"))
+	r.Equal([]string{
+		"GET /api/v9/applications/app/guilds/10/commands",
+		"POST /api/v9/applications/app/guilds/10/commands",
+		"DELETE /api/v9/applications/app/guilds/10/commands/7",
+	}, requests)
+	r.Equal(map[string]registerMapping{"101": {command: echo, guild: "10"}}, d.registeredCommand)
+
+	requests = nil
+	d.commandsGuild["20"] = []Command{&Executor{name: "Bad", description: "Invalid name"}}
+	err := d.SyncGuild("20")
+	r.IsType(InvalidCommandsError{}, err)
+	r.Nil(requests)
+}
@@ -12,6 +12,8 @@ type Request struct {
 	opts []*discordgo.ApplicationCommandInteractionDataOption
 	meta *MetaArgument
 	exec *Executor
+
+	fallback ResolveFallback
 }
 
 func (c *Request) Context() context.Context {
@@ -44,6 +46,8 @@ func (c *Request) Meta() *MetaArgument {
 	return c.meta
 }
 
+//Executor returns the executor of the chat command handling the request
+//it is nil for context menu commands, components and modals, as they aren't handled by an Executor
 func (c *Request) Executor() *Executor {
 	return c.exec
 }
@@ -0,0 +1,192 @@
+package diskoi
+
+import (
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+)
+
+//ResolveFallback decides where users, members, roles and channels are looked up
+//when they are missing from the resolved data that discord sends along with the interaction
+type ResolveFallback int
+
+const (
+	//ResolveFallbackState falls back to the state cache of the session, this is the default
+	ResolveFallbackState ResolveFallback = iota
+	//ResolveFallbackNone only uses the resolved data of the interaction
+	ResolveFallbackNone
+	//ResolveFallbackREST falls back to the state cache, then to the rest api
+	ResolveFallbackREST
+)
+
+//SetResolveFallback sets where values missing from the resolved data of interactions are looked up
+func (d *Diskoi) SetResolveFallback(fallback ResolveFallback) {
+	d.m.Lock()
+	defer d.m.Unlock()
+	d.fallback = fallback
+}
+
+func (d *Diskoi) ResolveFallback() ResolveFallback {
+	d.m.Lock()
+	defer d.m.Unlock()
+	return d.fallback
+}
+
+//resolver looks up the values of options, by reading the resolved data of the interaction first
+type resolver struct {
+	s        *discordgo.Session
+	i        *discordgo.InteractionCreate
+	fallback ResolveFallback
+}
+
+func (r resolver) resolved() *discordgo.ApplicationCommandInteractionDataResolved {
+	if r.i == nil || r.i.Interaction == nil {
+		return nil
+	}
+	id, ok := r.i.Data.(discordgo.ApplicationCommandInteractionData)
+	if !ok {
+		return nil
+	}
+	return id.Resolved
+}
+
+func (r resolver) guildID() string {
+	if r.i == nil || r.i.Interaction == nil {
+		return ""
+	}
+	return r.i.GuildID
+}
+
+func (r resolver) useState() bool {
+	return r.fallback != ResolveFallbackNone && r.s != nil && r.s.State != nil
+}
+
+func (r resolver) useREST() bool {
+	return r.fallback == ResolveFallbackREST && r.s != nil
+}
+
+func (r resolver) user(id string) (*discordgo.User, error) {
+	if res := r.resolved(); res != nil {
+		if u, ok := res.Users[id]; ok {
+			return u, nil
+		}
+	}
+	if r.useState() && r.guildID() != "" {
+		if m, err := r.s.State.Member(r.guildID(), id); err == nil && m.User != nil {
+			return m.User, nil
+		}
+	}
+	if r.useREST() {
+		u, err := r.s.User(id)
+		if err != nil {
+			return nil, DiscordAPIError{err: err}
+		}
+		return u, nil
+	}
+	return nil, newDiscordExpectationError(fmt.Sprintf(`cant resolve user "%s"`, id))
+}
+
+//member returns the member with the user filled in, or nil if the interaction is not within a guild
+func (r resolver) member(id string) (*discordgo.Member, error) {
+	guild := r.guildID()
+	if guild == "" {
+		return nil, nil
+	}
+	if res := r.resolved(); res != nil {
+		if m, ok := res.Members[id]; ok {
+			//resolved members are partial, so we fill in what we know
+			mc := *m
+			mc.GuildID = guild
+			if mc.User == nil {
+				mc.User = res.Users[id]
+			}
+			return &mc, nil
+		}
+	}
+	if r.useState() {
+		if m, err := r.s.State.Member(guild, id); err == nil {
+			return m, nil
+		}
+	}
+	if r.useREST() {
+		m, err := r.s.GuildMember(guild, id)
+		if err != nil {
+			return nil, DiscordAPIError{err: err}
+		}
+		return m, nil
+	}
+	return nil, newDiscordExpectationError(fmt.Sprintf(`cant resolve member "%s"`, id))
+}
+
+func (r resolver) role(id string) (*discordgo.Role, error) {
+	if res := r.resolved(); res != nil {
+		if role, ok := res.Roles[id]; ok {
+			return role, nil
+		}
+	}
+	guild := r.guildID()
+	if r.useState() && guild != "" {
+		if role, err := r.s.State.Role(guild, id); err == nil {
+			return role, nil
+		}
+	}
+	if r.useREST() && guild != "" {
+		roles, err := r.s.GuildRoles(guild)
+		if err != nil {
+			return nil, DiscordAPIError{err: err}
+		}
+		for _, role := range roles {
+			if role.ID == id {
+				return role, nil
+			}
+		}
+	}
+	return nil, newDiscordExpectationError(fmt.Sprintf(`cant resolve role "%s"`, id))
+}
+
+//channel returns the channel, which is partial when it comes from the resolved data
+func (r resolver) channel(id string) (*discordgo.Channel, error) {
+	if res := r.resolved(); res != nil {
+		if ch, ok := res.Channels[id]; ok {
+			return ch, nil
+		}
+	}
+	if r.useState() {
+		if ch, err := r.s.State.Channel(id); err == nil {
+			return ch, nil
+		}
+	}
+	if r.useREST() {
+		ch, err := r.s.Channel(id)
+		if err != nil {
+			return nil, DiscordAPIError{err: err}
+		}
+		return ch, nil
+	}
+	return nil, newDiscordExpectationError(fmt.Sprintf(`cant resolve channel "%s"`, id))
+}
+
+//mentionable resolves the id as a user or a role, users within guilds come with their member
+func (r resolver) mentionable(id string) (*Mentionable, error) {
+	if res := r.resolved(); res != nil {
+		if u, ok := res.Users[id]; ok {
+			return r.mentionableUser(id, u), nil
+		}
+		if role, ok := res.Roles[id]; ok {
+			return &Mentionable{Value: role}, nil
+		}
+	}
+	//fallbacks are only used once the resolved data has been checked for both
+	if role, err := r.role(id); err == nil {
+		return &Mentionable{Value: role}, nil
+	}
+	if u, err := r.user(id); err == nil {
+		return r.mentionableUser(id, u), nil
+	}
+	return nil, newDiscordExpectationError(fmt.Sprintf(`cant resolve mentionable "%s"`, id))
+}
+
+func (r resolver) mentionableUser(id string, u *discordgo.User) *Mentionable {
+	//a user mentioned within a guild may not be a member of it, so errors just leave the member empty
+	m, _ := r.member(id)
+	return &Mentionable{Value: u, member: m}
+}
@@ -0,0 +1,115 @@
+package diskoi
+
+import (
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/require"
+	"reflect"
+	"testing"
+)
+
+func TestResolver(t *testing.T) {
+	r := require.New(t)
+	user := &discordgo.User{ID: "1", Username: "foo"}
+	role := &discordgo.Role{ID: "2", Name: "bar"}
+	channel := &discordgo.Channel{ID: "3", Name: "baz"}
+	i := &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		GuildID: "10",
+		Data: discordgo.ApplicationCommandInteractionData{
+			Resolved: &discordgo.ApplicationCommandInteractionDataResolved{
+				Users:    map[string]*discordgo.User{"1": user},
+				Members:  map[string]*discordgo.Member{"1": {Nick: "nick"}},
+				Roles:    map[string]*discordgo.Role{"2": role},
+				Channels: map[string]*discordgo.Channel{"3": channel},
+			},
+		},
+	}}
+
+	stateUser := &discordgo.User{ID: "4", Username: "state"}
+	stateRole := &discordgo.Role{ID: "5", Name: "state"}
+	stateChannel := &discordgo.Channel{ID: "6", GuildID: "10", Name: "state"}
+	st := discordgo.NewState()
+	r.Nil(st.GuildAdd(&discordgo.Guild{ID: "10", Roles: []*discordgo.Role{stateRole}}))
+	r.Nil(st.ChannelAdd(stateChannel))
+	r.Nil(st.MemberAdd(&discordgo.Member{GuildID: "10", User: stateUser}))
+	s := &discordgo.Session{State: st}
+
+	res := resolver{s: s, i: i, fallback: ResolveFallbackNone}
+	u, err := res.user("1")
+	r.Nil(err)
+	r.Equal(user, u)
+	ro, err := res.role("2")
+	r.Nil(err)
+	r.Equal(role, ro)
+	ch, err := res.channel("3")
+	r.Nil(err)
+	r.Equal(channel, ch)
+	men, err := res.mentionable("2")
+	r.Nil(err)
+	r.Equal(&Mentionable{Value: role}, men)
+	_, ok := men.AsMember()
+	r.False(ok)
+	m, err := res.member("1")
+	r.Nil(err)
+	r.Equal(&discordgo.Member{GuildID: "10", Nick: "nick", User: user}, m)
+	men, err = res.mentionable("1")
+	r.Nil(err)
+	r.Equal(&Mentionable{Value: user, member: m}, men)
+
+	_, err = res.user("4")
+	r.Regexp(`cant resolve user "4"$`, err)
+	_, err = res.mentionable("5")
+	r.Regexp(`cant resolve mentionable "5"$`, err)
+
+	res.fallback = ResolveFallbackState
+	u, err = res.user("4")
+	r.Nil(err)
+	r.Equal(stateUser, u)
+	ro, err = res.role("5")
+	r.Nil(err)
+	r.Equal(stateRole, ro)
+	ch, err = res.channel("6")
+	r.Nil(err)
+	r.Equal(stateChannel.ID, ch.ID)
+	men, err = res.mentionable("4")
+	r.Nil(err)
+	mu, ok := men.AsUser()
+	r.True(ok)
+	r.Equal(stateUser, mu)
+	mm, ok := men.AsMember()
+	r.True(ok)
+	r.Equal(stateUser, mm.User)
+	_, err = res.channel("7")
+	r.Regexp(`cant resolve channel "7"$`, err)
+
+	type args struct {
+		User    *discordgo.User
+		Member  *discordgo.Member
+		Role    discordgo.Role
+		Channel *discordgo.Channel
+	}
+	cmdArg, err := analyzeCommandStruct(reflect.TypeOf(args{}), nil)
+	r.Nil(err)
+	v, err := reconstructCommandArgument(reflect.TypeOf(args{}), cmdArg, &Request{ic: i, fallback: ResolveFallbackNone,
+		opts: []*discordgo.ApplicationCommandInteractionDataOption{
+			{Name: "user", Type: discordgo.ApplicationCommandOptionUser, Value: "1"},
+			{Name: "member", Type: discordgo.ApplicationCommandOptionUser, Value: "1"},
+			{Name: "role", Type: discordgo.ApplicationCommandOptionRole, Value: "2"},
+			{Name: "channel", Type: discordgo.ApplicationCommandOptionChannel, Value: "3"},
+		}})
+	r.Nil(err)
+	r.Equal(args{User: user, Member: m, Role: *role, Channel: channel}, v.Interface())
+
+	dm := &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{Data: i.Data}}
+	v, err = reconstructCommandArgument(reflect.TypeOf(args{}), cmdArg, &Request{ic: dm, fallback: ResolveFallbackNone,
+		opts: []*discordgo.ApplicationCommandInteractionDataOption{
+			{Name: "member", Type: discordgo.ApplicationCommandOptionUser, Value: "1"},
+		}})
+	r.Nil(err)
+	r.Equal(args{}, v.Interface())
+
+	_, err = reconstructCommandArgument(reflect.TypeOf(args{}), cmdArg, &Request{ic: i, fallback: ResolveFallbackNone,
+		opts: []*discordgo.ApplicationCommandInteractionDataOption{
+			{Name: "user", Type: discordgo.ApplicationCommandOptionUser, Value: "4"},
+		}})
+	r.Regexp(`^resolving "User": .*cant resolve user "4"$`, err)
+}
@@ -0,0 +1,473 @@
+package diskoi
+
+import (
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+//SyncAction is the action SyncCommands will take on a command
+type SyncAction uint8
+
+const (
+	SyncActionUnchanged SyncAction = iota
+	SyncActionCreate
+	SyncActionUpdate
+	SyncActionDelete
+)
+
+func (a SyncAction) String() string {
+	switch a {
+	case SyncActionUnchanged:
+		return "unchanged"
+	case SyncActionCreate:
+		return "create"
+	case SyncActionUpdate:
+		return "update"
+	case SyncActionDelete:
+		return "delete"
+	default:
+		return fmt.Sprintf("SyncAction(%d)", a)
+	}
+}
+
+//SyncPlan is the set of changes needed to bring the remote commands in line with the local commands
+//it is produced by PlanSync, and applied by ApplySync
+type SyncPlan struct {
+	Scopes []*SyncScopePlan
+}
+
+//SyncScopePlan is the set of changes within a single scope, the Guild is empty for global commands
+type SyncScopePlan struct {
+	Guild   string
+	Changes []*SyncChange
+	//Stored is set when the scope is restored from the RegistryStore without contacting discord
+	Stored bool
+}
+
+//SyncChange is a planned change of a single command
+type SyncChange struct {
+	Action SyncAction
+	Name   string
+	Type   discordgo.ApplicationCommandType
+	//Differences lists the fields that differ between the local and remote command, only set when updating
+	Differences []string
+	//Local is the command that will be sent to discord, nil when deleting
+	Local *discordgo.ApplicationCommand
+	//Remote is the command currently on discord, nil when creating
+	Remote *discordgo.ApplicationCommand
+
+	command Command
+}
+
+//HasChanges returns true if applying the plan will make any changes on discord
+func (p *SyncPlan) HasChanges() bool {
+	for _, scope := range p.Scopes {
+		if scope.HasChanges() {
+			return true
+		}
+	}
+	return false
+}
+
+//HasChanges returns true if applying the scope will make any changes on discord
+func (p *SyncScopePlan) HasChanges() bool {
+	for _, c := range p.Changes {
+		if c.Action != SyncActionUnchanged {
+			return true
+		}
+	}
+	return false
+}
+
+//String returns the plan in a human readable format, omitting unchanged commands
+func (p *SyncPlan) String() string {
+	buf := strings.Builder{}
+	for _, scope := range p.Scopes {
+		if scope.Guild == "" {
+			buf.WriteString("global:\n")
+		} else {
+			buf.WriteString(fmt.Sprintf("guild %s:\n", scope.Guild))
+		}
+		for _, c := range scope.Changes {
+			var sym string
+			switch c.Action {
+			case SyncActionCreate:
+				sym = "+"
+			case SyncActionUpdate:
+				sym = "~"
+			case SyncActionDelete:
+				sym = "-"
+			default:
+				continue
+			}
+			buf.WriteString(fmt.Sprintf("  %s %s %s command \"%s\"", sym, c.Action.String(), commandTypeString(c.Type), c.Name))
+			if len(c.Differences) > 0 {
+				buf.WriteString(fmt.Sprintf(" (%s)", strings.Join(c.Differences, ", ")))
+			}
+			buf.WriteString("\n")
+		}
+	}
+	return buf.String()
+}
+
+//PlanSync compares the local commands against the commands on discord and returns the changes SyncCommands would make
+func (d *Diskoi) PlanSync() (*SyncPlan, error) {
+	d.m.Lock()
+	defer d.m.Unlock()
+	return d.planSyncUnsafe()
+}
+
+//ApplySync applies a plan produced by PlanSync
+func (d *Diskoi) ApplySync(plan *SyncPlan) error {
+	d.m.Lock()
+	defer d.m.Unlock()
+	return d.applySyncUnsafe(plan)
+}
+
+func (d *Diskoi) planSyncUnsafe() (*SyncPlan, error) {
+	plan := &SyncPlan{}
+	scope, err := d.planScopeUnsafe("", d.scopeCommandsUnsafe(""))
+	if err != nil {
+		return nil, err
+	}
+	plan.Scopes = append(plan.Scopes, scope)
+	for _, guild := range d.scopeGuildsUnsafe() {
+		scope, err := d.planScopeUnsafe(guild, d.scopeCommandsUnsafe(guild))
+		if err != nil {
+			return nil, err
+		}
+		plan.Scopes = append(plan.Scopes, scope)
+	}
+	return plan, nil
+}
+
+func (d *Diskoi) planScopeUnsafe(guild string, cs []Command) (*SyncScopePlan, error) {
+	scope, err := d.restoreScopeUnsafe(guild, cs)
+	if err != nil || scope != nil {
+		return scope, err
+	}
+	rc, err := d.s.ApplicationCommands(d.s.State.User.ID, guild)
+	if err != nil {
+		return nil, DiscordAPIError{err: err}
+	}
+	return planScope(guild, cs, rc, d.localizer), nil
+}
+
+func (d *Diskoi) applySyncUnsafe(plan *SyncPlan) error {
+	for _, scope := range plan.Scopes {
+		if d.syncMode == SyncModeBulkOverwrite && scope.HasChanges() {
+			cs := make([]Command, 0, len(scope.Changes))
+			for _, c := range scope.Changes {
+				if c.command != nil {
+					cs = append(cs, c.command)
+				}
+			}
+			err := d.bulkOverwriteUnsafe(scope.Guild, cs)
+			if err != nil {
+				return err
+			}
+			err = d.storeScopeUnsafe(scope.Guild, cs)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		cs := make([]Command, 0, len(scope.Changes))
+		for _, c := range scope.Changes {
+			switch c.Action {
+			case SyncActionUnchanged:
+				d.mapRegisteredUnsafe(c.Remote.ID, scope.Guild, c.command)
+			case SyncActionCreate:
+				cc, err := d.s.ApplicationCommandCreate(d.s.State.User.ID, scope.Guild, c.Local)
+				if err != nil {
+					return DiscordAPIError{err: err}
+				}
+				d.mapRegisteredUnsafe(cc.ID, scope.Guild, c.command)
+			case SyncActionUpdate:
+				cc, err := d.s.ApplicationCommandEdit(d.s.State.User.ID, scope.Guild, c.Remote.ID, c.Local)
+				if err != nil {
+					return DiscordAPIError{err: err}
+				}
+				d.mapRegisteredUnsafe(cc.ID, scope.Guild, c.command)
+			case SyncActionDelete:
+				err := d.s.ApplicationCommandDelete(d.s.State.User.ID, scope.Guild, c.Remote.ID)
+				if err != nil {
+					return DiscordAPIError{err: err}
+				}
+				delete(d.registeredCommand, c.Remote.ID)
+			}
+			if c.command != nil {
+				cs = append(cs, c.command)
+			}
+		}
+		if !scope.Stored {
+			err := d.storeScopeUnsafe(scope.Guild, cs)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+//mapRegisteredUnsafe maps the id to the command, other ids of the command within the scope are stale and removed
+//so they wont be routed or saved into the store
+func (d *Diskoi) mapRegisteredUnsafe(id string, guild string, c Command) {
+	for oldID, rc := range d.registeredCommand {
+		if oldID != id && rc.guild == guild && rc.command == c {
+			delete(d.registeredCommand, oldID)
+		}
+	}
+	d.registeredCommand[id] = registerMapping{
+		command: c,
+		guild:   guild,
+	}
+}
+
+//planScope compares the local commands against the remote commands of a single scope
+//changes are ordered by the local commands, followed by deletions ordered by name
+func planScope(guild string, cs []Command, rc []*discordgo.ApplicationCommand, l Localizer) *SyncScopePlan {
+	cMap := make(map[commandKey]*discordgo.ApplicationCommand, len(rc))
+	for _, cmd := range rc {
+		cMap[commandKey{typ: cmd.Type, name: cmd.Name}] = cmd
+	}
+
+	scope := &SyncScopePlan{Guild: guild}
+	eMap := make(map[commandKey]struct{}, len(cs))
+	for _, c := range cs {
+		key := commandKey{typ: c.Type(), name: c.Name()}
+		eMap[key] = struct{}{}
+		change := &SyncChange{
+			Action:  SyncActionCreate,
+			Name:    c.Name(),
+			Type:    c.Type(),
+			Local:   c.applicationCommand(l),
+			command: c,
+		}
+		if remote, ok := cMap[key]; ok {
+			change.Remote = remote
+			change.Differences = commandDifferences(change.Local, remote)
+			if len(change.Differences) == 0 {
+				change.Action = SyncActionUnchanged
+			} else {
+				change.Action = SyncActionUpdate
+			}
+		}
+		scope.Changes = append(scope.Changes, change)
+	}
+
+	var deletes []*SyncChange
+	for key, cmd := range cMap {
+		if _, ok := eMap[key]; !ok {
+			deletes = append(deletes, &SyncChange{
+				Action: SyncActionDelete,
+				Name:   cmd.Name,
+				Type:   cmd.Type,
+				Remote: cmd,
+			})
+		}
+	}
+	sort.Slice(deletes, func(i, j int) bool {
+		if deletes[i].Name == deletes[j].Name {
+			return deletes[i].Type < deletes[j].Type
+		}
+		return deletes[i].Name < deletes[j].Name
+	})
+	scope.Changes = append(scope.Changes, deletes...)
+	return scope
+}
+
+//commandDifferences returns the paths of the fields that differ between the local and remote command
+func commandDifferences(local *discordgo.ApplicationCommand, remote *discordgo.ApplicationCommand) []string {
+	var diff []string
+	if local.Description != remote.Description {
+		diff = append(diff, "description")
+	}
+	if !localizationsEqual(derefLocalizations(local.NameLocalizations), derefLocalizations(remote.NameLocalizations)) {
+		diff = append(diff, "name_localizations")
+	}
+	if !localizationsEqual(derefLocalizations(local.DescriptionLocalizations), derefLocalizations(remote.DescriptionLocalizations)) {
+		diff = append(diff, "description_localizations")
+	}
+	if !int64PtrEqual(local.DefaultMemberPermissions, remote.DefaultMemberPermissions) {
+		diff = append(diff, "default_member_permissions")
+	}
+	//discord defaults dm permission to true and nsfw to false when they are unset
+	if boolOr(local.DMPermission, true) != boolOr(remote.DMPermission, true) {
+		diff = append(diff, "dm_permission")
+	}
+	if boolOr(local.NSFW, false) != boolOr(remote.NSFW, false) {
+		diff = append(diff, "nsfw")
+	}
+	diff = append(diff, optionsDifferences("options", local.Options, remote.Options)...)
+	return diff
+}
+
+//optionsDifferences compares options one by one, and recursively into subcommands and subcommand groups
+//the returned paths are prefixed with the option name, e.g. "options.target.required"
+func optionsDifferences(path string, local []*discordgo.ApplicationCommandOption, remote []*discordgo.ApplicationCommandOption) []string {
+	if len(local) != len(remote) {
+		return []string{path}
+	}
+	var diff []string
+	for i, l := range local {
+		r := remote[i]
+		p := path + "." + l.Name
+		if l.Name != r.Name {
+			diff = append(diff, p+".name")
+			continue
+		}
+		if l.Type != r.Type {
+			diff = append(diff, p+".type")
+			continue
+		}
+		if l.Description != r.Description {
+			diff = append(diff, p+".description")
+		}
+		if !localizationsEqual(l.NameLocalizations, r.NameLocalizations) {
+			diff = append(diff, p+".name_localizations")
+		}
+		if !localizationsEqual(l.DescriptionLocalizations, r.DescriptionLocalizations) {
+			diff = append(diff, p+".description_localizations")
+		}
+		if l.Required != r.Required {
+			diff = append(diff, p+".required")
+		}
+		if l.Autocomplete != r.Autocomplete {
+			diff = append(diff, p+".autocomplete")
+		}
+		if !choicesEqual(l.Choices, r.Choices) {
+			diff = append(diff, p+".choices")
+		}
+		if !channelTypesEqual(l.ChannelTypes, r.ChannelTypes) {
+			diff = append(diff, p+".channel_types")
+		}
+		if !float64PtrEqual(l.MinValue, r.MinValue) {
+			diff = append(diff, p+".min_value")
+		}
+		if l.MaxValue != r.MaxValue {
+			diff = append(diff, p+".max_value")
+		}
+		if !intPtrEqual(l.MinLength, r.MinLength) {
+			diff = append(diff, p+".min_length")
+		}
+		if l.MaxLength != r.MaxLength {
+			diff = append(diff, p+".max_length")
+		}
+		diff = append(diff, optionsDifferences(p+".options", l.Options, r.Options)...)
+	}
+	return diff
+}
+
+func choicesEqual(local []*discordgo.ApplicationCommandOptionChoice, remote []*discordgo.ApplicationCommandOptionChoice) bool {
+	if len(local) != len(remote) {
+		return false
+	}
+	for i, l := range local {
+		r := remote[i]
+		if l.Name != r.Name || !choiceValueEqual(l.Value, r.Value) || !localizationsEqual(l.NameLocalizations, r.NameLocalizations) {
+			return false
+		}
+	}
+	return true
+}
+
+//choiceValueEqual compares choice values, numbers are compared as float64 since discord returns them as such
+func choiceValueEqual(local interface{}, remote interface{}) bool {
+	lv, rv := reflect.ValueOf(local), reflect.ValueOf(remote)
+	lf, lok := toFloat64(lv)
+	rf, rok := toFloat64(rv)
+	if lok && rok {
+		return lf == rf
+	}
+	return reflect.DeepEqual(local, remote)
+}
+
+func toFloat64(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+//localizationsEqual compares localizations, where nil and empty are equal
+func localizationsEqual(local map[discordgo.Locale]string, remote map[discordgo.Locale]string) bool {
+	if len(local) != len(remote) {
+		return false
+	}
+	for locale, l := range local {
+		if r, ok := remote[locale]; !ok || l != r {
+			return false
+		}
+	}
+	return true
+}
+
+func derefLocalizations(l *map[discordgo.Locale]string) map[discordgo.Locale]string {
+	if l == nil {
+		return nil
+	}
+	return *l
+}
+
+func channelTypesEqual(local []discordgo.ChannelType, remote []discordgo.ChannelType) bool {
+	if len(local) != len(remote) {
+		return false
+	}
+	for i := range local {
+		if local[i] != remote[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func float64PtrEqual(a *float64, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func int64PtrEqual(a *int64, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func boolOr(b *bool, def bool) bool {
+	if b == nil {
+		return def
+	}
+	return *b
+}
+
+func intPtrEqual(a *int, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func commandTypeString(t discordgo.ApplicationCommandType) string {
+	switch t {
+	case discordgo.ChatApplicationCommand:
+		return "chat"
+	case discordgo.UserApplicationCommand:
+		return "user"
+	case discordgo.MessageApplicationCommand:
+		return "message"
+	default:
+		return fmt.Sprintf("ApplicationCommandType(%d)", t)
+	}
+}
@@ -0,0 +1,197 @@
+package diskoi
+
+import (
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestPlanScope(t *testing.T) {
+	r := require.New(t)
+	type echoArgs struct {
+		Text string `diskoi:"description:Text to echo"`
+	}
+	ping := MustNewExecutor("ping", "Ping the bot", func() {})
+	echo := MustNewExecutor("echo", "Echo text", func(a echoArgs) {})
+	info := MustNewUserCommandExecutor("info", func() {})
+	remote := []*discordgo.ApplicationCommand{
+		{ID: "1", Type: discordgo.ChatApplicationCommand, Name: "ping", Description: "Ping the bot"},
+		{ID: "2", Type: discordgo.ChatApplicationCommand, Name: "echo", Description: "Echo"},
+		{ID: "3", Type: discordgo.ChatApplicationCommand, Name: "info", Description: "Old info"},
+		{ID: "4", Type: discordgo.MessageApplicationCommand, Name: "report"},
+	}
+
+	scope := planScope("10", []Command{ping, echo, info}, remote, nil)
+	r.Equal("10", scope.Guild)
+	r.Len(scope.Changes, 5)
+
+	want := []struct {
+		action SyncAction
+		name   string
+		typ    discordgo.ApplicationCommandType
+		diff   []string
+	}{
+		{SyncActionUnchanged, "ping", discordgo.ChatApplicationCommand, nil},
+		{SyncActionUpdate, "echo", discordgo.ChatApplicationCommand, []string{"description", "options"}},
+		{SyncActionCreate, "info", discordgo.UserApplicationCommand, nil},
+		{SyncActionDelete, "info", discordgo.ChatApplicationCommand, nil},
+		{SyncActionDelete, "report", discordgo.MessageApplicationCommand, nil},
+	}
+	for i, w := range want {
+		c := scope.Changes[i]
+		r.Equal(w.action, c.Action, "action on #%d", i)
+		r.Equal(w.name, c.Name, "name on #%d", i)
+		r.Equal(w.typ, c.Type, "type on #%d", i)
+		r.Equal(w.diff, c.Differences, "differences on #%d", i)
+	}
+	r.Equal(remote[0], scope.Changes[0].Remote)
+	r.Nil(scope.Changes[2].Remote)
+	r.Nil(scope.Changes[3].Local)
+
+	plan := &SyncPlan{Scopes: []*SyncScopePlan{{}, scope}}
+	r.True(plan.HasChanges())
+	r.Equal(`global:
+guild 10:
+  ~ update chat command "echo" (description, options)
+  + create user command "info"
+  - delete chat command "info"
+  - delete message command "report"
+`, plan.String())
+
+	r.False((&SyncPlan{Scopes: []*SyncScopePlan{planScope("", []Command{ping}, remote[:1], nil)}}).HasChanges())
+}
+
+func TestCommandDifferences(t *testing.T) {
+	one, two := 1.0, 2
+	yes, no := true, false
+	perms := int64(discordgo.PermissionAdministrator)
+	cases := []struct {
+		name   string
+		local  *discordgo.ApplicationCommand
+		remote *discordgo.ApplicationCommand
+		want   []string
+	}{
+		{
+			name:   "equal with normalized choices",
+			local:  &discordgo.ApplicationCommand{Description: "foo", Options: []*discordgo.ApplicationCommandOption{{Name: "a", Type: discordgo.ApplicationCommandOptionInteger, Choices: []*discordgo.ApplicationCommandOptionChoice{{Name: "one", Value: 1}}, ChannelTypes: []discordgo.ChannelType{}}}},
+			remote: &discordgo.ApplicationCommand{Description: "foo", Options: []*discordgo.ApplicationCommandOption{{Name: "a", Type: discordgo.ApplicationCommandOptionInteger, Choices: []*discordgo.ApplicationCommandOptionChoice{{Name: "one", Value: float64(1)}}}}},
+		}, {
+			name:   "empty options",
+			local:  &discordgo.ApplicationCommand{Options: []*discordgo.ApplicationCommandOption{}},
+			remote: &discordgo.ApplicationCommand{},
+		}, {
+			name:   "option count",
+			local:  &discordgo.ApplicationCommand{Description: "foo", Options: []*discordgo.ApplicationCommandOption{{Name: "a"}}},
+			remote: &discordgo.ApplicationCommand{Description: "bar"},
+			want:   []string{"description", "options"},
+		}, {
+			name:   "renamed",
+			local:  &discordgo.ApplicationCommand{Options: []*discordgo.ApplicationCommandOption{{Name: "a"}}},
+			remote: &discordgo.ApplicationCommand{Options: []*discordgo.ApplicationCommandOption{{Name: "b"}}},
+			want:   []string{"options.a.name"},
+		}, {
+			name: "fields",
+			local: &discordgo.ApplicationCommand{Options: []*discordgo.ApplicationCommandOption{{
+				Name: "a", Description: "x", Required: true, Autocomplete: true,
+				Choices:      []*discordgo.ApplicationCommandOptionChoice{{Name: "one", Value: "1"}},
+				ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText},
+				MinValue:     &one, MaxValue: 5, MinLength: &two, MaxLength: 10,
+			}}},
+			remote: &discordgo.ApplicationCommand{Options: []*discordgo.ApplicationCommandOption{{Name: "a"}}},
+			want: []string{"options.a.description", "options.a.required", "options.a.autocomplete", "options.a.choices",
+				"options.a.channel_types", "options.a.min_value", "options.a.max_value", "options.a.min_length", "options.a.max_length"},
+		}, {
+			name: "nested",
+			local: &discordgo.ApplicationCommand{Options: []*discordgo.ApplicationCommandOption{{
+				Name: "grp", Type: discordgo.ApplicationCommandOptionSubCommandGroup, Options: []*discordgo.ApplicationCommandOption{{
+					Name: "sub", Type: discordgo.ApplicationCommandOptionSubCommand, Options: []*discordgo.ApplicationCommandOption{{
+						Name: "opt", Type: discordgo.ApplicationCommandOptionString, Required: true,
+					}},
+				}},
+			}}},
+			remote: &discordgo.ApplicationCommand{Options: []*discordgo.ApplicationCommandOption{{
+				Name: "grp", Type: discordgo.ApplicationCommandOptionSubCommandGroup, Options: []*discordgo.ApplicationCommandOption{{
+					Name: "sub", Type: discordgo.ApplicationCommandOptionSubCommand, Options: []*discordgo.ApplicationCommandOption{{
+						Name: "opt", Type: discordgo.ApplicationCommandOptionString,
+					}},
+				}},
+			}}},
+			want: []string{"options.grp.options.sub.options.opt.required"},
+		}, {
+			name:   "default permissions",
+			local:  &discordgo.ApplicationCommand{DMPermission: &yes, NSFW: &no},
+			remote: &discordgo.ApplicationCommand{},
+		}, {
+			name:   "permissions",
+			local:  &discordgo.ApplicationCommand{DefaultMemberPermissions: &perms, DMPermission: &no, NSFW: &yes},
+			remote: &discordgo.ApplicationCommand{},
+			want:   []string{"default_member_permissions", "dm_permission", "nsfw"},
+		}, {
+			name: "localizations",
+			local: &discordgo.ApplicationCommand{
+				NameLocalizations: &map[discordgo.Locale]string{discordgo.German: "bannen"},
+				Options: []*discordgo.ApplicationCommandOption{{
+					Name: "a", DescriptionLocalizations: map[discordgo.Locale]string{discordgo.German: "x"},
+					Choices: []*discordgo.ApplicationCommandOptionChoice{{Name: "one", Value: "1", NameLocalizations: map[discordgo.Locale]string{discordgo.German: "eins"}}},
+				}},
+			},
+			remote: &discordgo.ApplicationCommand{
+				DescriptionLocalizations: &map[discordgo.Locale]string{},
+				Options: []*discordgo.ApplicationCommandOption{{
+					Name: "a", Choices: []*discordgo.ApplicationCommandOptionChoice{{Name: "one", Value: "1"}},
+				}},
+			},
+			want: []string{"name_localizations", "options.a.description_localizations", "options.a.choices"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, commandDifferences(tc.local, tc.remote))
+		})
+	}
+}
+
+//roundTripFunc answers the requests of a session without contacting discord
+type roundTripFunc func(req *http.Request) *http.Response
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req), nil
+}
+
+func TestApplySyncRegisteredCommands(t *testing.T) {
+	r := require.New(t)
+	s, err := discordgo.New("Bot token")
+	r.Nil(err)
+	s.State.User = &discordgo.User{ID: "app"}
+	s.Client = &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+		if req.Method == http.MethodDelete {
+			return &http.Response{StatusCode: http.StatusNoContent, Body: io.NopCloser(strings.NewReader("")), Request: req}
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"id":"3","name":"ping"}`)), Request: req}
+	})}
+
+	ping := MustNewExecutor("ping", "Ping the bot", func() {})
+	old := MustNewExecutor("old", "Removed command", func() {})
+	store := NewMemoryRegistryStore()
+	d := NewDiskoi()
+	d.s = s
+	d.SetRegistryStore(store)
+	d.registeredCommand["1"] = registerMapping{command: ping}
+	d.registeredCommand["2"] = registerMapping{command: old}
+	d.registeredCommand["10"] = registerMapping{command: ping, guild: "100"}
+
+	r.Nil(d.applySyncUnsafe(&SyncPlan{Scopes: []*SyncScopePlan{{Changes: []*SyncChange{
+		{Action: SyncActionCreate, Name: "ping", Local: ping.applicationCommand(nil), command: ping},
+		{Action: SyncActionDelete, Name: "old", Remote: &discordgo.ApplicationCommand{ID: "2", Name: "old"}},
+	}}}}))
+	r.Equal(map[string]registerMapping{
+		"3":  {command: ping},
+		"10": {command: ping, guild: "100"},
+	}, d.registeredCommand)
+	scope, err := store.LoadScope("")
+	r.Nil(err)
+	r.Equal([]StoredCommand{{ID: "3", Type: discordgo.ChatApplicationCommand, Name: "ping"}}, scope.Commands)
+}
@@ -1,22 +1,31 @@
 package diskoi
 
 import (
+	"context"
+	"fmt"
 	"github.com/bwmarrin/discordgo"
+	"io"
+	"net/http"
 	"sync"
 )
 
 type Command interface {
 	Name() string
 	Description() string
-	execute(s *discordgo.Session, i *discordgo.InteractionCreate, pre Chain) error
-	autocomplete(s *discordgo.Session, i *discordgo.InteractionCreate) ([]*discordgo.ApplicationCommandOptionChoice, error)
-	applicationCommand() *discordgo.ApplicationCommand
+	Type() discordgo.ApplicationCommandType
+	execute(s *discordgo.Session, i *discordgo.InteractionCreate, pre Chain, fb ResolveFallback) error
+	autocomplete(s *discordgo.Session, i *discordgo.InteractionCreate, fb ResolveFallback) ([]*discordgo.ApplicationCommandOptionChoice, error)
+	applicationCommand(l Localizer) *discordgo.ApplicationCommand
+	//Validate checks the command against the limits of discord, the error is an InvalidCommandError
+	Validate() error
 	lock()
 }
 
 //Mentionable is an instance of something that could be a Role or a User
 type Mentionable struct {
 	Value interface{}
+	//member is the member of the mentioned user, if the user is mentioned within a guild
+	member *discordgo.Member
 }
 
 func (m *Mentionable) AsUser() (*discordgo.User, bool) {
@@ -29,6 +38,39 @@ func (m *Mentionable) AsRole() (*discordgo.Role, bool) {
 	return r, ok
 }
 
+//AsMember returns the member of the mentioned user, it's false if a role is mentioned or the user isn't a member
+func (m *Mentionable) AsMember() (*discordgo.Member, bool) {
+	return m.member, m.member != nil
+}
+
+//Attachment is a file uploaded as a command option, the file is only downloaded when opened
+type Attachment struct {
+	*discordgo.MessageAttachment
+	client *http.Client
+}
+
+//Open downloads the attachment with the http client of the session, the caller must close the returned reader
+func (a *Attachment) Open(ctx context.Context) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf(`downloading attachment "%s": %w`, a.Filename, err)
+	}
+	client := a.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf(`downloading attachment "%s": %w`, a.Filename, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf(`downloading attachment "%s": unexpected status "%s"`, a.Filename, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+//errorHandler handles errors from executing interactions, cmd will be nil if the error originates from a component or modal
 type errorHandler func(s *discordgo.Session, i *discordgo.InteractionCreate, cmd Command, err error)
 
 type rawInteractionHandler func(*discordgo.Session, *discordgo.InteractionCreate)
@@ -39,6 +81,26 @@ func withRWMutex(m *sync.RWMutex, fn func()) {
 	fn()
 }
 
+//commandPermissions holds the fields of a top level command that decide where and to whom the command is shown
+//they are ignored on executors used as subcommands
+type commandPermissions struct {
+	defaultMemberPermissions *int64
+	dmPermission             *bool
+	nsfw                     *bool
+}
+
+func (p commandPermissions) applyTo(a *discordgo.ApplicationCommand) {
+	a.DefaultMemberPermissions = p.defaultMemberPermissions
+	a.DMPermission = p.dmPermission
+	a.NSFW = p.nsfw
+}
+
+//commandKey identifies a command within a scope, as commands of different types can share a name
+type commandKey struct {
+	typ  discordgo.ApplicationCommandType
+	name string
+}
+
 type registerMapping struct {
 	command Command
 	guild   string
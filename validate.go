@@ -0,0 +1,154 @@
+package diskoi
+
+import (
+	"fmt"
+	"github.com/bwmarrin/discordgo"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxDescriptionLength     = 100
+	maxContextMenuNameLength = 32
+	//maxCommandLength is the limit of the combined length of the names, descriptions and values within a command
+	maxCommandLength = 4000
+)
+
+//InvalidCommandError lists every way a command breaks the limits of discord
+type InvalidCommandError struct {
+	name string
+	errs []error
+}
+
+func (e InvalidCommandError) Error() string {
+	msgs := make([]string, 0, len(e.errs))
+	for _, err := range e.errs {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf(`invalid command "%s": %s`, e.name, strings.Join(msgs, "; "))
+}
+
+//Errors returns the violations, each prefixed by the path of the command or option
+func (e InvalidCommandError) Errors() []error {
+	return e.errs
+}
+
+//InvalidCommandsError lists the commands that break the limits of discord when syncing
+type InvalidCommandsError struct {
+	errs []error
+}
+
+func (e InvalidCommandsError) Error() string {
+	msgs := make([]string, 0, len(e.errs))
+	for _, err := range e.errs {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("validating commands: %s", strings.Join(msgs, "; "))
+}
+
+//Errors returns the error of each invalid command, usually an InvalidCommandError
+func (e InvalidCommandsError) Errors() []error {
+	return e.errs
+}
+
+func (e *Executor) Validate() error {
+	return validateApplicationCommand(e.applicationCommand(nil))
+}
+
+func (c *CommandGroup) Validate() error {
+	return validateApplicationCommand(c.applicationCommand(nil))
+}
+
+func (c *contextMenuExecutor) Validate() error {
+	return validateApplicationCommand(c.applicationCommand(nil))
+}
+
+//commandValidator collects the violations of a command
+type commandValidator struct {
+	errs   []error
+	length int
+}
+
+func (v *commandValidator) errorf(path []string, format string, a ...interface{}) {
+	v.errs = append(v.errs, fmt.Errorf("%s: %s", errPath(path), fmt.Sprintf(format, a...)))
+}
+
+//validateApplicationCommand checks the generated command against the limits of discord
+func validateApplicationCommand(a *discordgo.ApplicationCommand) error {
+	v := &commandValidator{}
+	path := []string{a.Name}
+	v.length += utf8.RuneCountInString(a.Name) + utf8.RuneCountInString(a.Description)
+	if a.Type == discordgo.ChatApplicationCommand {
+		v.checkName(path, a.Name)
+		v.checkDescription(path, a.Description)
+		v.checkOptions(path, a.Options)
+	} else if l := utf8.RuneCountInString(a.Name); l < 1 || l > maxContextMenuNameLength {
+		v.errorf(path, "name should be 1 to %d characters long", maxContextMenuNameLength)
+	}
+	if v.length > maxCommandLength {
+		v.errorf(path, "combined length of names, descriptions and values is %d, over %d", v.length, maxCommandLength)
+	}
+	if len(v.errs) > 0 {
+		return InvalidCommandError{name: a.Name, errs: v.errs}
+	}
+	return nil
+}
+
+func (v *commandValidator) checkName(path []string, name string) {
+	if !optionNamePattern.MatchString(name) {
+		v.errorf(path, `name "%s" should be 1 to 32 letters, numbers, "-" or "_"`, name)
+	} else if strings.ToLower(name) != name {
+		v.errorf(path, `name "%s" should be lowercase`, name)
+	}
+}
+
+func (v *commandValidator) checkDescription(path []string, description string) {
+	if l := utf8.RuneCountInString(description); l < 1 || l > maxDescriptionLength {
+		v.errorf(path, "description should be 1 to %d characters long", maxDescriptionLength)
+	}
+}
+
+//checkOptions checks the options of a command, subcommand or subcommand group, and the options within them
+func (v *commandValidator) checkOptions(path []string, opts []*discordgo.ApplicationCommandOption) {
+	if len(opts) > maxOptions {
+		v.errorf(path, "%d options, over %d", len(opts), maxOptions)
+	}
+	seen := make(map[string]struct{}, len(opts))
+	optional := false
+	for _, o := range opts {
+		oPath := append(append(make([]string, 0, len(path)+1), path...), o.Name)
+		v.length += utf8.RuneCountInString(o.Name) + utf8.RuneCountInString(o.Description)
+		v.checkName(oPath, o.Name)
+		v.checkDescription(oPath, o.Description)
+		if _, ok := seen[o.Name]; ok {
+			v.errorf(oPath, "name is used by another option")
+		}
+		seen[o.Name] = struct{}{}
+
+		switch o.Type {
+		case discordgo.ApplicationCommandOptionSubCommand, discordgo.ApplicationCommandOptionSubCommandGroup:
+			v.checkOptions(oPath, o.Options)
+			continue
+		}
+		if o.Required && optional {
+			v.errorf(oPath, "required option after optional options")
+		}
+		optional = optional || !o.Required
+		if len(o.Choices) > 0 && o.Autocomplete {
+			v.errorf(oPath, "choices cant be used with autocomplete")
+		}
+		v.checkChoices(oPath, o.Choices)
+	}
+}
+
+func (v *commandValidator) checkChoices(path []string, choices []*discordgo.ApplicationCommandOptionChoice) {
+	if len(choices) > maxOptionChoices {
+		v.errorf(path, "%d choices, over %d", len(choices), maxOptionChoices)
+	}
+	for _, c := range choices {
+		if l := utf8.RuneCountInString(c.Name); l < 1 || l > maxOptionChoiceLength {
+			v.errorf(path, `choice name "%s" should be 1 to %d characters long`, c.Name, maxOptionChoiceLength)
+		}
+		v.length += utf8.RuneCountInString(c.Name) + utf8.RuneCountInString(fmt.Sprint(c.Value))
+	}
+}
@@ -0,0 +1,95 @@
+package diskoi
+
+import (
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestValidateApplicationCommand(t *testing.T) {
+	r := require.New(t)
+	type banArgs struct {
+		User   *discordgo.User `diskoi:"required,description:User to ban"`
+		Reason string          `diskoi:"description:Reason of the ban"`
+	}
+	ban := MustNewExecutor("ban", "Ban a user", func(banArgs) {})
+	r.Nil(ban.Validate())
+	r.Nil(MustNewUserCommandExecutor("Ban User", func() {}).Validate())
+
+	type badArgs struct {
+		Reason string          `diskoi:"choices:a|b"`
+		User   *discordgo.User `diskoi:"required,description:User to ban"`
+	}
+	bad := MustNewExecutor("Ban", "", func(badArgs) {})
+	bad.cmdArg[0].Choices = append(bad.cmdArg[0].Choices, &discordgo.ApplicationCommandOptionChoice{Name: "", Value: "c"})
+	err := bad.Validate()
+	r.IsType(InvalidCommandError{}, err)
+	r.Equal([]string{
+		`/Ban: name "Ban" should be lowercase`,
+		`/Ban: description should be 1 to 100 characters long`,
+		`/Ban reason: description should be 1 to 100 characters long`,
+		`/Ban reason: choice name "" should be 1 to 100 characters long`,
+	}, errorStrings(err.(InvalidCommandError).Errors()))
+	r.Regexp(`^invalid command "Ban": /Ban: name "Ban" should be lowercase; `, err)
+
+	unordered := ban.applicationCommand(nil)
+	unordered.Options[0], unordered.Options[1] = unordered.Options[1], unordered.Options[0]
+	err = validateApplicationCommand(unordered)
+	r.Equal([]string{`/ban user: required option after optional options`}, errorStrings(err.(InvalidCommandError).Errors()))
+
+	type pickArgs struct {
+		Color string `diskoi:"choices:red|blue,description:Color to pick"`
+	}
+	pick := MustNewExecutor("pick", "Pick a color", func(pickArgs) {}).applicationCommand(nil)
+	pick.Options[0].Autocomplete = true
+	err = validateApplicationCommand(pick)
+	r.Equal([]string{`/pick color: choices cant be used with autocomplete`}, errorStrings(err.(InvalidCommandError).Errors()))
+
+	grp := NewCommandGroup("mod", "Moderation")
+	for i := 0; i < 26; i++ {
+		grp.AddSubcommand(MustNewExecutor("sub"+strconv.Itoa(i), "Subcommand", func() {}))
+	}
+	sub := NewSubcommandGroup("users", strings.Repeat("a", 101))
+	sub.AddSubcommand(MustNewExecutor("ban", "Ban a user", func(banArgs) {}))
+	grp.AddSubcommandGroup(sub)
+	err = grp.Validate()
+	r.Equal([]string{
+		`/mod: 27 options, over 25`,
+		`/mod users: description should be 1 to 100 characters long`,
+	}, errorStrings(err.(InvalidCommandError).Errors()))
+
+	long := MustNewExecutor("long", strings.Repeat("a", 100), func(struct {
+		A string `diskoi:"description:Option a"`
+	}) {
+	})
+	long.cmdArg[0].Choices = make([]*discordgo.ApplicationCommandOptionChoice, 0, 25)
+	for i := 0; i < 25; i++ {
+		long.cmdArg[0].Choices = append(long.cmdArg[0].Choices, &discordgo.ApplicationCommandOptionChoice{
+			Name: strings.Repeat("a", 100), Value: strings.Repeat("b", 100),
+		})
+	}
+	r.Regexp(`^invalid command "long": /long: combined length of names, descriptions and values is 5113, over 4000$`, long.Validate())
+
+	d := NewDiskoi()
+	r.Equal(err, d.AddCommand(grp))
+	r.Nil(d.FindCommandByName("mod"))
+	r.False(grp.Locked())
+	r.Nil(d.AddCommand(ban))
+	d.commands = append(d.commands, bad)
+	err = d.SyncCommands()
+	r.Regexp(`^validating commands: invalid command "Ban": `, err)
+	r.IsType(InvalidCommandsError{}, err)
+	errs := err.(InvalidCommandsError).Errors()
+	r.Len(errs, 1)
+	r.Equal(bad.Validate(), errs[0])
+}
+
+func errorStrings(errs []error) []string {
+	s := make([]string, 0, len(errs))
+	for _, err := range errs {
+		s = append(s, err.Error())
+	}
+	return s
+}